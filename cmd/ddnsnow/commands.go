@@ -0,0 +1,130 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+)
+
+// recordArgs parses the TYPE and VALUE arguments of a command. The value is
+// optional unless valueRequired.
+func (c *command) recordArgs(valueRequired bool) (ddnsnow.Record, error) {
+	if len(c.args) < 1 || len(c.args) > 2 || (valueRequired && len(c.args) != 2) {
+		return ddnsnow.Record{}, &usageError{msg: "expected arguments: TYPE VALUE"}
+	}
+
+	record := ddnsnow.Record{
+		Type: ddnsnow.RecordType(strings.ToUpper(c.args[0])),
+	}
+	if !slices.Contains(ddnsnow.RecordTypes, record.Type) {
+		return ddnsnow.Record{}, &usageError{msg: fmt.Sprintf("unsupported record type %q", c.args[0])}
+	}
+	if len(c.args) == 2 {
+		record.Value = c.args[1]
+	}
+	return record, nil
+}
+
+func runGet(ctx context.Context, c *command) error {
+	record, err := c.recordArgs(false)
+	if err != nil {
+		return err
+	}
+	if record.Value == "" && (record.Type == ddnsnow.RecordTypeNS || record.Type == ddnsnow.RecordTypeTXT) {
+		return &usageError{msg: fmt.Sprintf("VALUE is required for %s records", record.Type)}
+	}
+
+	record, err = c.client.GetRecord(ctx, record)
+	if err != nil {
+		return err
+	}
+
+	return c.writeRecords([]ddnsnow.Record{record})
+}
+
+func runList(ctx context.Context, c *command) error {
+	if len(c.args) != 0 {
+		return &usageError{msg: "unexpected arguments"}
+	}
+	filter := ddnsnow.RecordType(strings.ToUpper(c.recordType))
+	if filter != "" && !slices.Contains(ddnsnow.RecordTypes, filter) {
+		return &usageError{msg: fmt.Sprintf("unsupported record type %q", c.recordType)}
+	}
+
+	settings, err := c.client.GetSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	records := []ddnsnow.Record{}
+	for _, record := range settingsRecords(settings) {
+		if filter == "" || record.Type == filter {
+			records = append(records, record)
+		}
+	}
+	return c.writeRecords(records)
+}
+
+func runAdd(ctx context.Context, c *command) error {
+	record, err := c.recordArgs(true)
+	if err != nil {
+		return err
+	}
+
+	return c.client.CreateRecord(ctx, record)
+}
+
+func runRemove(ctx context.Context, c *command) error {
+	record, err := c.recordArgs(true)
+	if err != nil {
+		return err
+	}
+
+	return c.client.DeleteRecord(ctx, record)
+}
+
+func runSetWildcard(ctx context.Context, c *command) error {
+	if len(c.args) != 1 {
+		return &usageError{msg: "expected arguments: true|false"}
+	}
+	enabled, err := strconv.ParseBool(c.args[0])
+	if err != nil {
+		return &usageError{msg: fmt.Sprintf("invalid wildcard setting %q, must be true or false", c.args[0])}
+	}
+
+	return c.client.SetWildcard(ctx, enabled)
+}
+
+func runExport(ctx context.Context, c *command) error {
+	if len(c.args) != 0 {
+		return &usageError{msg: "unexpected arguments"}
+	}
+
+	settings, err := c.client.GetSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.writeSettings(settings)
+}
+
+// settingsRecords returns the records of settings ordered by type.
+func settingsRecords(settings *ddnsnow.Settings) []ddnsnow.Record {
+	var records []ddnsnow.Record
+	for _, typ := range ddnsnow.RecordTypes {
+		for _, value := range settings.Records[typ] {
+			records = append(records, ddnsnow.Record{
+				Type:  typ,
+				Value: value,
+			})
+		}
+	}
+	return records
+}
@@ -0,0 +1,162 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+)
+
+// config holds the settings of the command, which may be read from the
+// configuration file, the environment and the flags.
+type config struct {
+	Username     string `json:"username"`
+	PasswordHash string `json:"password_hash"`
+	Password     string `json:"password"`
+	Server       string `json:"server"`
+}
+
+// merge overrides c with the settings of other which are set. The password
+// hash and the password are alternatives, so setting either of them
+// overrides both.
+func (c *config) merge(other config) {
+	if other.Username != "" {
+		c.Username = other.Username
+	}
+	if other.PasswordHash != "" || other.Password != "" {
+		c.PasswordHash = other.PasswordHash
+		c.Password = other.Password
+	}
+	if other.Server != "" {
+		c.Server = other.Server
+	}
+}
+
+// envConfig returns the settings of the environment.
+func envConfig() config {
+	return config{
+		Username:     os.Getenv("DDNSNOW_USERNAME"),
+		PasswordHash: os.Getenv("DDNSNOW_PASSWORD_HASH"),
+		Password:     os.Getenv("DDNSNOW_PASSWORD"),
+		Server:       os.Getenv("DDNSNOW_SERVER"),
+	}
+}
+
+// loadConfig reads the configuration file at path. Without a path, the
+// default file is read if it exists.
+func loadConfig(path string) (config, error) {
+	var c config
+
+	explicit := path != ""
+	if !explicit {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return c, nil
+		}
+		path = filepath.Join(dir, "ddnsnow", "config.json")
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return c, nil
+	}
+	if err != nil {
+		return c, &usageError{msg: fmt.Sprintf("read configuration file: %v", err)}
+	}
+
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, &usageError{msg: fmt.Sprintf("parse configuration file %s: %v", path, err)}
+	}
+	return c, nil
+}
+
+// command holds what a command needs to run.
+type command struct {
+	args   []string
+	stdout io.Writer
+	stderr io.Writer
+	output string
+
+	// recordType filters the records listed.
+	recordType string
+	// watch configures the watch command.
+	watch watchOptions
+
+	client ddnsnow.Client
+}
+
+// newCommand parses the flags of the command name and configures the
+// client.
+func newCommand(name string, args []string, stdout, stderr io.Writer) (*command, error) {
+	c := &command{
+		stdout: stdout,
+		stderr: stderr,
+	}
+
+	var flagConfig config
+	var configPath string
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&flagConfig.Username, "username", "", "")
+	flags.StringVar(&flagConfig.PasswordHash, "password-hash", "", "")
+	flags.StringVar(&flagConfig.Password, "password", "", "")
+	flags.StringVar(&flagConfig.Server, "server", "", "")
+	flags.StringVar(&configPath, "config", os.Getenv("DDNSNOW_CONFIG"), "")
+	flags.StringVar(&c.output, "output", "table", "")
+	switch name {
+	case "list":
+		flags.StringVar(&c.recordType, "type", "", "")
+	case "watch":
+		c.watch.register(flags)
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+		return nil, &usageError{msg: err.Error()}
+	}
+	c.args = flags.Args()
+
+	switch {
+	case c.output == "json", c.output == "table":
+	case c.output == "zone" && name == "export":
+	default:
+		return nil, &usageError{msg: fmt.Sprintf("unsupported output %q, must be json or table, or zone for export", c.output)}
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg.merge(envConfig())
+	cfg.merge(flagConfig)
+
+	switch {
+	case cfg.Username == "":
+		return nil, &usageError{msg: "missing username, set -username, DDNSNOW_USERNAME or the configuration file"}
+	case cfg.PasswordHash == "" && cfg.Password == "":
+		return nil, &usageError{msg: "missing password, set -password-hash or -password, DDNSNOW_PASSWORD_HASH or DDNSNOW_PASSWORD, or the configuration file"}
+	case cfg.PasswordHash != "" && cfg.Password != "":
+		return nil, &usageError{msg: "both a password hash and a password are set, set only one of them"}
+	}
+
+	var opts []ddnsnow.ClientOption
+	if cfg.Password != "" {
+		opts = append(opts, ddnsnow.WithPassword(cfg.Password))
+	}
+	c.client, err = ddnsnow.NewClient(&cfg.Username, &cfg.PasswordHash, &cfg.Server, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
@@ -0,0 +1,179 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"os/exec"
+	"strings"
+
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+)
+
+// detector detects the current address of the host.
+type detector interface {
+	detect(ctx context.Context) (netip.Addr, error)
+}
+
+// parseDetector returns the detector of the addresses of typ, A or AAAA,
+// described by spec. It returns nil for "none".
+func parseDetector(spec string, typ ddnsnow.RecordType) (detector, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch {
+	case spec == "none":
+		return nil, nil
+	case kind == "interface" && arg != "":
+		return &interfaceDetector{name: arg, typ: typ}, nil
+	case kind == "command" && len(strings.Fields(arg)) > 0:
+		return &commandDetector{args: strings.Fields(arg), typ: typ}, nil
+	case kind == "http" || kind == "https":
+		return newHTTPDetector(spec, typ), nil
+	default:
+		return nil, &usageError{msg: fmt.Sprintf("invalid detector %q", spec)}
+	}
+}
+
+// parseAddr parses text as an address of typ.
+func parseAddr(text string, typ ddnsnow.RecordType) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(text))
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("parse address: %w", err)
+	}
+	addr = addr.Unmap()
+	if !hasType(addr, typ) {
+		return netip.Addr{}, fmt.Errorf("%s is not an address of an %s record", addr, typ)
+	}
+	return addr, nil
+}
+
+// hasType tells whether addr is an address of typ.
+func hasType(addr netip.Addr, typ ddnsnow.RecordType) bool {
+	if typ == ddnsnow.RecordTypeA {
+		return addr.Is4()
+	}
+	return addr.Is6() && !addr.Is4In6()
+}
+
+// interfaceDetector detects the address of a network interface.
+type interfaceDetector struct {
+	name string
+	typ  ddnsnow.RecordType
+}
+
+func (d *interfaceDetector) detect(_ context.Context) (netip.Addr, error) {
+	iface, err := net.InterfaceByName(d.name)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("interface %s: %w", d.name, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("interface %s addresses: %w", d.name, err)
+	}
+
+	addr, ok := selectAddr(addrs, d.typ)
+	if !ok {
+		return netip.Addr{}, fmt.Errorf("interface %s has no global address of an %s record", d.name, d.typ)
+	}
+	return addr, nil
+}
+
+// selectAddr returns the first global unicast address of typ in addrs,
+// preferring public addresses over private ones.
+func selectAddr(addrs []net.Addr, typ ddnsnow.RecordType) (netip.Addr, bool) {
+	var private netip.Addr
+	for _, a := range addrs {
+		prefix, err := netip.ParsePrefix(a.String())
+		if err != nil {
+			continue
+		}
+		addr := prefix.Addr().Unmap()
+		if !hasType(addr, typ) || !addr.IsGlobalUnicast() {
+			continue
+		}
+		if !addr.IsPrivate() {
+			return addr, true
+		}
+		if !private.IsValid() {
+			private = addr
+		}
+	}
+	return private, private.IsValid()
+}
+
+// commandDetector detects the address printed by a command.
+type commandDetector struct {
+	args []string
+	typ  ddnsnow.RecordType
+}
+
+func (d *commandDetector) detect(ctx context.Context) (netip.Addr, error) {
+	output, err := exec.CommandContext(ctx, d.args[0], d.args[1:]...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return netip.Addr{}, fmt.Errorf("command %s: %w: %s", d.args[0], err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return netip.Addr{}, fmt.Errorf("command %s: %w", d.args[0], err)
+	}
+
+	return parseAddr(string(output), d.typ)
+}
+
+// httpDetector detects the address in the response of an HTTP echo
+// endpoint, which is requested directly over the IP version of its record
+// type. Proxies are not used, because the endpoint would echo their address.
+type httpDetector struct {
+	url        string
+	typ        ddnsnow.RecordType
+	httpClient *http.Client
+}
+
+func newHTTPDetector(url string, typ ddnsnow.RecordType) *httpDetector {
+	network := "tcp4"
+	if typ == ddnsnow.RecordTypeAAAA {
+		network = "tcp6"
+	}
+	dialer := &net.Dialer{}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+
+	return &httpDetector{
+		url:        url,
+		typ:        typ,
+		httpClient: &http.Client{Transport: transport},
+	}
+}
+
+func (d *httpDetector) detect(ctx context.Context) (netip.Addr, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", d.url, nil)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("http request construction: %w", err)
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("http request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return netip.Addr{}, fmt.Errorf("%s responded with status %d", d.url, resp.StatusCode)
+	}
+	// An address is short, so anything longer is not one.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("read body: %w", err)
+	}
+
+	return parseAddr(string(body), d.typ)
+}
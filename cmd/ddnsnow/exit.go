@@ -0,0 +1,54 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"errors"
+
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+)
+
+// Exit statuses of the command, as documented in the usage.
+const (
+	exitOK = iota
+	exitError
+	exitUsage
+	exitUnauthorized
+	exitNotFound
+	exitConflict
+	exitInvalid
+)
+
+// usageError is returned for invalid command lines and configurations.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// exitCode maps err to the exit status of the command.
+func exitCode(err error) int {
+	var usageErr *usageError
+	var conflictErr *ddnsnow.ConflictError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.Is(err, ddnsnow.ErrUnauthorized):
+		return exitUnauthorized
+	case errors.Is(err, ddnsnow.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, ddnsnow.ErrAlreadyExists),
+		errors.Is(err, ddnsnow.ErrCNAMEConflict),
+		errors.As(err, &conflictErr):
+		return exitConflict
+	case errors.Is(err, ddnsnow.ErrInvalidRecord):
+		return exitInvalid
+	default:
+		return exitError
+	}
+}
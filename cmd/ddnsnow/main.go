@@ -0,0 +1,118 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Command ddnsnow inspects and changes the records of a DDNS Now domain.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const usage = `Usage: ddnsnow <command> [flags] [arguments]
+
+Commands:
+  get TYPE [VALUE]        Print a record. VALUE is required for NS and TXT records.
+  list [-type TYPE]       Print every record, or those of TYPE.
+  add TYPE VALUE          Add a record.
+  remove TYPE VALUE       Remove a record.
+  set-wildcard true|false Enable or disable wildcard resolution.
+  export                  Print every record and the wildcard setting.
+  watch                   Keep the A and AAAA records at the detected addresses.
+
+Every command accepts the following flags:
+  -username string        DDNS Now username (DDNSNOW_USERNAME)
+  -password-hash string   DDNS Now password hash (DDNSNOW_PASSWORD_HASH)
+  -password string        DDNS Now password (DDNSNOW_PASSWORD)
+  -server string          DDNS Now server URL (DDNSNOW_SERVER)
+  -config string          Configuration file (DDNSNOW_CONFIG), defaults to
+                          ddnsnow/config.json in the user configuration directory
+  -output json|table      Output format, defaults to table. export also
+                          accepts zone for a BIND-style zone file fragment
+
+The watch command accepts the following flags:
+  -ipv4 detector          Detector of the IPv4 address, defaults to https://api.ipify.org
+  -ipv6 detector          Detector of the IPv6 address, defaults to none
+  -interval duration      Interval between detections, defaults to 5m
+  -jitter fraction        Random deviation of the interval, defaults to 0.1
+  -timeout duration       Timeout of a detection and update, defaults to 1m
+  -state string           File remembering the published addresses, so that
+                          DDNS Now is only contacted when they change
+  -once                   Detect and update once, then exit
+
+A detector is one of:
+  none                    Leave the record alone
+  interface:NAME          The address of the network interface NAME
+  command:COMMAND         The output of COMMAND, split into arguments on spaces
+  http://... https://...  The body of the response of the URL
+
+Flags take precedence over environment variables, which take precedence over
+the configuration file.
+
+Exit status:
+  0  success
+  1  unexpected error
+  2  invalid usage or configuration
+  3  DDNS Now rejected the credentials
+  4  the record does not exist
+  5  the change conflicts with existing records
+  6  the record is invalid
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run runs the command line args and returns the exit status.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	var cmd func(context.Context, *command) error
+	switch args[0] {
+	case "get":
+		cmd = runGet
+	case "list":
+		cmd = runList
+	case "add":
+		cmd = runAdd
+	case "remove":
+		cmd = runRemove
+	case "set-wildcard":
+		cmd = runSetWildcard
+	case "export":
+		cmd = runExport
+	case "watch":
+		cmd = runWatch
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "ddnsnow: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+
+	c, err := newCommand(args[0], args[1:], stdout, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
+	if err == nil {
+		err = cmd(ctx, c)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "ddnsnow: %v\n", err)
+	}
+	return exitCode(err)
+}
@@ -0,0 +1,266 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+	"terraform-provider-ddnsnow/pkg/ddnsnow/ddnsnowtest"
+)
+
+// isolate keeps the environment and the user configuration of the machine
+// running the tests out of the command.
+func isolate(t *testing.T) {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("AppData", dir)
+	for _, key := range []string{"DDNSNOW_USERNAME", "DDNSNOW_PASSWORD_HASH", "DDNSNOW_PASSWORD", "DDNSNOW_SERVER", "DDNSNOW_CONFIG"} {
+		t.Setenv(key, "")
+	}
+}
+
+// runWithServer runs the command line args against testServer.
+func runWithServer(testServer *ddnsnowtest.Server, args ...string) (int, string, string) {
+	credentials := []string{
+		"-username", ddnsnowtest.DefaultUsername,
+		"-password-hash", ddnsnowtest.DefaultPasswordHash,
+		"-server", testServer.URL,
+	}
+	if len(args) > 0 {
+		args = slices.Concat(args[:1], credentials, args[1:])
+	}
+	return runArgs(args...)
+}
+
+func runArgs(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunList(t *testing.T) {
+	isolate(t)
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "192.0.2.1")
+	testServer.SetRecords(ddnsnow.RecordTypeTXT, "one", "two")
+
+	code, stdout, stderr := runWithServer(testServer, "list", "-output", "json")
+	if code != exitOK {
+		t.Fatalf("unexpected exit status %d: %s", code, stderr)
+	}
+
+	var records []recordOutput
+	if err := json.Unmarshal([]byte(stdout), &records); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	want := []recordOutput{
+		{Type: ddnsnow.RecordTypeA, Value: "192.0.2.1"},
+		{Type: ddnsnow.RecordTypeTXT, Value: "one"},
+		{Type: ddnsnow.RecordTypeTXT, Value: "two"},
+	}
+	if !slices.Equal(records, want) {
+		t.Fatalf("unexpected records: %v", records)
+	}
+}
+
+func TestRunListTable(t *testing.T) {
+	isolate(t)
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "192.0.2.1")
+	testServer.SetRecords(ddnsnow.RecordTypeTXT, "one")
+
+	code, stdout, stderr := runWithServer(testServer, "list", "-type", "txt")
+	if code != exitOK {
+		t.Fatalf("unexpected exit status %d: %s", code, stderr)
+	}
+
+	want := "TYPE  VALUE\nTXT   one\n"
+	if stdout != want {
+		t.Fatalf("unexpected output: %q", stdout)
+	}
+}
+
+func TestRunAddAndRemove(t *testing.T) {
+	isolate(t)
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+
+	if code, _, stderr := runWithServer(testServer, "add", "TXT", "record"); code != exitOK {
+		t.Fatalf("unexpected exit status of add %d: %s", code, stderr)
+	}
+	if got := testServer.Records(ddnsnow.RecordTypeTXT); !slices.Equal(got, []string{"record"}) {
+		t.Fatalf("unexpected TXT records after add: %v", got)
+	}
+
+	if code, _, stderr := runWithServer(testServer, "remove", "TXT", "record"); code != exitOK {
+		t.Fatalf("unexpected exit status of remove %d: %s", code, stderr)
+	}
+	if got := testServer.Records(ddnsnow.RecordTypeTXT); len(got) != 0 {
+		t.Fatalf("unexpected TXT records after remove: %v", got)
+	}
+}
+
+func TestRunRemoveFailsForOtherValue(t *testing.T) {
+	isolate(t)
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "192.0.2.1")
+
+	code, _, _ := runWithServer(testServer, "remove", "A", "192.0.2.2")
+	if code != exitNotFound {
+		t.Fatalf("unexpected exit status: %d", code)
+	}
+	if got := testServer.Records(ddnsnow.RecordTypeA); !slices.Equal(got, []string{"192.0.2.1"}) {
+		t.Fatalf("unexpected A records: %v", got)
+	}
+}
+
+func TestRunSetWildcardAndExport(t *testing.T) {
+	isolate(t)
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeCNAME, "example.com")
+
+	if code, _, stderr := runWithServer(testServer, "set-wildcard", "true"); code != exitOK {
+		t.Fatalf("unexpected exit status of set-wildcard %d: %s", code, stderr)
+	}
+
+	code, stdout, stderr := runWithServer(testServer, "export", "-output", "json")
+	if code != exitOK {
+		t.Fatalf("unexpected exit status of export %d: %s", code, stderr)
+	}
+	var settings settingsOutput
+	if err := json.Unmarshal([]byte(stdout), &settings); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !settings.Wildcard {
+		t.Fatalf("wildcard is not enabled")
+	}
+	if got := settings.Records[ddnsnow.RecordTypeCNAME]; !slices.Equal(got, []string{"example.com"}) {
+		t.Fatalf("unexpected CNAME records: %v", got)
+	}
+}
+
+func TestRunExportZone(t *testing.T) {
+	isolate(t)
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "192.0.2.1")
+	testServer.SetRecords(ddnsnow.RecordTypeTXT, `say "hi"`)
+
+	code, stdout, stderr := runWithServer(testServer, "export", "-output", "zone")
+	if code != exitOK {
+		t.Fatalf("unexpected exit status %d: %s", code, stderr)
+	}
+
+	want := "@\tIN\tA\t192.0.2.1\n@\tIN\tTXT\t\"say \\\"hi\\\"\"\n"
+	if stdout != want {
+		t.Fatalf("unexpected output: %q", stdout)
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	isolate(t)
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeCNAME, "example.com")
+
+	for name, tc := range map[string]struct {
+		args []string
+		want int
+	}{
+		"not found":       {[]string{"get", "TXT", "missing"}, exitNotFound},
+		"conflict":        {[]string{"add", "A", "192.0.2.1"}, exitConflict},
+		"invalid":         {[]string{"add", "TXT", strings.Repeat("x", 1000)}, exitInvalid},
+		"missing value":   {[]string{"add", "TXT"}, exitUsage},
+		"unknown type":    {[]string{"get", "MX"}, exitUsage},
+		"invalid output":  {[]string{"list", "-output", "yaml"}, exitUsage},
+		"zone output":     {[]string{"list", "-output", "zone"}, exitUsage},
+		"unknown command": {[]string{"rename"}, exitUsage},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if code, _, stderr := runWithServer(testServer, tc.args...); code != tc.want {
+				t.Fatalf("unexpected exit status %d: %s", code, stderr)
+			}
+		})
+	}
+}
+
+func TestRunFailsWithWrongCredentials(t *testing.T) {
+	isolate(t)
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+
+	code, _, _ := runArgs("list", "-username", "domain", "-password-hash", "wrong", "-server", testServer.URL)
+	if code != exitUnauthorized {
+		t.Fatalf("unexpected exit status: %d", code)
+	}
+}
+
+func TestRunFailsWithoutCredentials(t *testing.T) {
+	isolate(t)
+
+	code, _, stderr := runArgs("list")
+	if code != exitUsage {
+		t.Fatalf("unexpected exit status: %d", code)
+	}
+	if !strings.Contains(stderr, "missing username") {
+		t.Fatalf("unexpected error: %s", stderr)
+	}
+}
+
+func TestRunReadsConfigurationFile(t *testing.T) {
+	isolate(t)
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "192.0.2.1")
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	data, err := json.Marshal(config{
+		Username: ddnsnowtest.DefaultUsername,
+		Password: "wrong",
+		Server:   "http://127.0.0.1:0",
+	})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	// The environment overrides the password and the server of the file.
+	t.Setenv("DDNSNOW_CONFIG", path)
+	t.Setenv("DDNSNOW_PASSWORD_HASH", ddnsnowtest.DefaultPasswordHash)
+	t.Setenv("DDNSNOW_SERVER", testServer.URL)
+
+	code, stdout, stderr := runArgs("get", "A")
+	if code != exitOK {
+		t.Fatalf("unexpected exit status %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "192.0.2.1") {
+		t.Fatalf("unexpected output: %q", stdout)
+	}
+}
+
+func TestRunFailsWithMissingConfigurationFile(t *testing.T) {
+	isolate(t)
+
+	code, _, _ := runArgs("list", "-config", filepath.Join(t.TempDir(), "missing.json"))
+	if code != exitUsage {
+		t.Fatalf("unexpected exit status: %d", code)
+	}
+}
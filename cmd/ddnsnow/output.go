@@ -0,0 +1,74 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+)
+
+// recordOutput is the JSON output of a record.
+type recordOutput struct {
+	Type  ddnsnow.RecordType `json:"type"`
+	Value string             `json:"value"`
+}
+
+// settingsOutput is the JSON output of the settings.
+type settingsOutput struct {
+	Records  map[ddnsnow.RecordType][]string `json:"records"`
+	Wildcard bool                            `json:"wildcard"`
+}
+
+func (c *command) writeJSON(v any) error {
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// writeRecords prints records in the output format.
+func (c *command) writeRecords(records []ddnsnow.Record) error {
+	if c.output == "json" {
+		output := make([]recordOutput, 0, len(records))
+		for _, record := range records {
+			output = append(output, recordOutput{
+				Type:  record.Type,
+				Value: record.Value,
+			})
+		}
+		return c.writeJSON(output)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tVALUE")
+	for _, record := range records {
+		fmt.Fprintf(w, "%s\t%s\n", record.Type, record.Value)
+	}
+	return w.Flush()
+}
+
+// writeSettings prints settings in the output format.
+func (c *command) writeSettings(settings *ddnsnow.Settings) error {
+	if c.output == "zone" {
+		return ddnsnow.WriteZone(c.stdout, settings)
+	}
+	if c.output == "json" {
+		output := settingsOutput{
+			Records:  map[ddnsnow.RecordType][]string{},
+			Wildcard: settings.EnableWildcard,
+		}
+		for _, typ := range ddnsnow.RecordTypes {
+			output.Records[typ] = append([]string{}, settings.Records[typ]...)
+		}
+		return c.writeJSON(output)
+	}
+
+	if err := c.writeRecords(settingsRecords(settings)); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(c.stdout, "\nWildcard: %t\n", settings.EnableWildcard)
+	return err
+}
@@ -0,0 +1,248 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"math/rand/v2"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"time"
+
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+)
+
+// watchTypes lists the record types kept at the detected addresses.
+var watchTypes = []ddnsnow.RecordType{ddnsnow.RecordTypeA, ddnsnow.RecordTypeAAAA}
+
+// watchOptions holds the flags of the watch command.
+type watchOptions struct {
+	detectors map[ddnsnow.RecordType]*string
+	interval  time.Duration
+	jitter    float64
+	timeout   time.Duration
+	statePath string
+	once      bool
+}
+
+func (o *watchOptions) register(flags *flag.FlagSet) {
+	o.detectors = map[ddnsnow.RecordType]*string{
+		ddnsnow.RecordTypeA:    flags.String("ipv4", "https://api.ipify.org", ""),
+		ddnsnow.RecordTypeAAAA: flags.String("ipv6", "none", ""),
+	}
+	flags.DurationVar(&o.interval, "interval", 5*time.Minute, "")
+	flags.Float64Var(&o.jitter, "jitter", 0.1, "")
+	flags.DurationVar(&o.timeout, "timeout", time.Minute, "")
+	flags.StringVar(&o.statePath, "state", "", "")
+	flags.BoolVar(&o.once, "once", false, "")
+}
+
+// watchState maps the record types to the addresses last published, so that
+// DDNS Now is not contacted again until the detected addresses change.
+type watchState map[ddnsnow.RecordType]string
+
+// loadState reads the state file at path, which may not exist yet.
+func loadState(path string) (watchState, error) {
+	state := watchState{}
+	if path == "" {
+		return state, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read state file: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parse state file %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// save replaces the state file at path, so that it is never left half
+// written.
+func (s watchState) save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("marshal state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("write state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write state file: %w", err)
+	}
+	return nil
+}
+
+// watcher keeps the A and AAAA records at the detected addresses.
+type watcher struct {
+	client    ddnsnow.Client
+	detectors map[ddnsnow.RecordType]detector
+	state     watchState
+	statePath string
+	logger    *log.Logger
+}
+
+func runWatch(ctx context.Context, c *command) error {
+	o := c.watch
+	if len(c.args) != 0 {
+		return &usageError{msg: "unexpected arguments"}
+	}
+	if o.interval <= 0 {
+		return &usageError{msg: "the interval must be positive"}
+	}
+	if o.jitter < 0 || o.jitter >= 1 {
+		return &usageError{msg: "the jitter must be at least 0 and less than 1"}
+	}
+	if o.timeout <= 0 {
+		return &usageError{msg: "the timeout must be positive"}
+	}
+
+	w := &watcher{
+		client:    c.client,
+		detectors: map[ddnsnow.RecordType]detector{},
+		statePath: o.statePath,
+		logger:    log.New(c.stderr, "ddnsnow: ", log.LstdFlags),
+	}
+	for _, typ := range watchTypes {
+		d, err := parseDetector(*o.detectors[typ], typ)
+		if err != nil {
+			return err
+		}
+		if d != nil {
+			w.detectors[typ] = d
+		}
+	}
+	if len(w.detectors) == 0 {
+		return &usageError{msg: "both detectors are none, so there is nothing to watch"}
+	}
+
+	var err error
+	w.state, err = loadState(o.statePath)
+	if err != nil {
+		return err
+	}
+
+	for {
+		// A shutdown lets the running update finish, so that the records
+		// and the state file stay consistent.
+		syncCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), o.timeout)
+		err := w.sync(syncCtx)
+		cancel()
+		if o.once || errors.Is(err, ddnsnow.ErrUnauthorized) {
+			return err
+		}
+		if err != nil {
+			w.logger.Print(err)
+		}
+
+		timer := time.NewTimer(jittered(o.interval, o.jitter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			w.logger.Print("shutting down")
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// jittered returns interval deviated randomly by up to jitter of it, so that
+// many hosts started together do not contact DDNS Now at the same time.
+func jittered(interval time.Duration, jitter float64) time.Duration {
+	return interval + time.Duration((rand.Float64()*2-1)*jitter*float64(interval))
+}
+
+// sync detects the addresses and publishes those which have changed.
+// Addresses which cannot be detected are left alone.
+func (w *watcher) sync(ctx context.Context) error {
+	var errs []error
+	detected := map[ddnsnow.RecordType]netip.Addr{}
+	changed := false
+	for _, typ := range watchTypes {
+		d, ok := w.detectors[typ]
+		if !ok {
+			continue
+		}
+		addr, err := d.detect(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("detect %s address: %w", typ, err))
+			continue
+		}
+		detected[typ] = addr
+		if w.state[typ] != addr.String() {
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := w.publish(ctx, detected); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// publish updates the records which differ from the detected addresses in a
+// single submission and remembers the addresses as published.
+func (w *watcher) publish(ctx context.Context, detected map[ddnsnow.RecordType]netip.Addr) error {
+	settings, err := w.client.GetSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	var remove, add []ddnsnow.Record
+	for _, typ := range watchTypes {
+		addr, ok := detected[typ]
+		if !ok {
+			continue
+		}
+		current := settings.Records[typ]
+		if len(current) > 0 {
+			if published, err := netip.ParseAddr(current[0]); err == nil && published == addr {
+				continue
+			}
+			remove = append(remove, ddnsnow.Record{Type: typ, Value: current[0]})
+		}
+		add = append(add, ddnsnow.Record{Type: typ, Value: addr.String()})
+	}
+
+	if len(add) > 0 {
+		if err := w.client.UpdateRecords(ctx, remove, add); err != nil {
+			return err
+		}
+		for _, record := range add {
+			w.logger.Printf("updated the %s record to %s", record.Type, record.Value)
+		}
+	}
+
+	for typ, addr := range detected {
+		w.state[typ] = addr.String()
+	}
+	if w.statePath == "" {
+		return nil
+	}
+	return w.state.save(w.statePath)
+}
@@ -0,0 +1,195 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+	"terraform-provider-ddnsnow/pkg/ddnsnow/ddnsnowtest"
+)
+
+// newEchoServer returns an HTTP echo endpoint responding with addr.
+func newEchoServer(addr string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, addr)
+	}))
+}
+
+func TestRunWatchOnce(t *testing.T) {
+	isolate(t)
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "192.0.2.1")
+	testServer.SetRecords(ddnsnow.RecordTypeTXT, "record")
+	echoServer := newEchoServer("192.0.2.10")
+	defer echoServer.Close()
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	code, _, stderr := runWithServer(testServer, "watch", "-once", "-ipv4", echoServer.URL, "-state", statePath)
+	if code != exitOK {
+		t.Fatalf("unexpected exit status %d: %s", code, stderr)
+	}
+
+	if got := testServer.Records(ddnsnow.RecordTypeA); !slices.Equal(got, []string{"192.0.2.10"}) {
+		t.Fatalf("unexpected A records: %v", got)
+	}
+	if got := testServer.Records(ddnsnow.RecordTypeTXT); !slices.Equal(got, []string{"record"}) {
+		t.Fatalf("unexpected TXT records: %v", got)
+	}
+	state, err := loadState(statePath)
+	if err != nil {
+		t.Fatalf("loadState: %v", err)
+	}
+	if state[ddnsnow.RecordTypeA] != "192.0.2.10" {
+		t.Fatalf("unexpected state: %v", state)
+	}
+}
+
+func TestRunWatchSkipsPublishedAddresses(t *testing.T) {
+	isolate(t)
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	echoServer := newEchoServer("192.0.2.10")
+	defer echoServer.Close()
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	var reads int
+	for i := range 2 {
+		code, _, stderr := runWithServer(testServer, "watch", "-once", "-ipv4", echoServer.URL, "-state", statePath)
+		if code != exitOK {
+			t.Fatalf("unexpected exit status %d: %s", code, stderr)
+		}
+		if i == 0 {
+			reads = testServer.Requests(http.MethodGet)
+		}
+	}
+
+	// The second run finds the address in the state file, so it does not
+	// contact DDNS Now at all.
+	if got := testServer.Requests(http.MethodPost); got != 1 {
+		t.Fatalf("unexpected number of updates: %d", got)
+	}
+	if got := testServer.Requests(http.MethodGet); got != reads {
+		t.Fatalf("unexpected number of reads: %d", got)
+	}
+}
+
+func TestRunWatchLeavesMatchingRecords(t *testing.T) {
+	isolate(t)
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeAAAA, "2001:db8::1")
+	dir := t.TempDir()
+	command := filepath.Join(dir, "detect")
+	if err := os.WriteFile(command, []byte("#!/bin/sh\necho 2001:0db8::1\n"), 0o700); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	code, _, stderr := runWithServer(testServer, "watch", "-once", "-ipv4", "none", "-ipv6", "command:"+command)
+	if code != exitOK {
+		t.Fatalf("unexpected exit status %d: %s", code, stderr)
+	}
+
+	if got := testServer.Requests(http.MethodPost); got != 0 {
+		t.Fatalf("unexpected number of updates: %d", got)
+	}
+}
+
+func TestRunWatchStopsOnCancel(t *testing.T) {
+	isolate(t)
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	echoServer := newEchoServer("192.0.2.10")
+	defer echoServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan int)
+	var stderr bytes.Buffer
+	go func() {
+		done <- run(ctx, []string{
+			"watch",
+			"-username", ddnsnowtest.DefaultUsername,
+			"-password-hash", ddnsnowtest.DefaultPasswordHash,
+			"-server", testServer.URL,
+			"-ipv4", echoServer.URL,
+			"-interval", "1h",
+		}, &bytes.Buffer{}, &stderr)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(testServer.Records(ddnsnow.RecordTypeA)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("the record was not updated")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case code := <-done:
+		if code != exitOK {
+			t.Fatalf("unexpected exit status %d: %s", code, stderr.String())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("watch did not stop")
+	}
+}
+
+func TestRunWatchFailsWithInvalidFlags(t *testing.T) {
+	isolate(t)
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+
+	for _, args := range [][]string{
+		{"watch", "-ipv4", "dns:example.com"},
+		{"watch", "-ipv4", "none", "-ipv6", "none"},
+		{"watch", "-interval", "0s"},
+		{"watch", "-jitter", "1"},
+	} {
+		if code, _, stderr := runWithServer(testServer, args...); code != exitUsage {
+			t.Fatalf("unexpected exit status of %v %d: %s", args, code, stderr)
+		}
+	}
+}
+
+func TestSelectAddr(t *testing.T) {
+	addrs := []net.Addr{
+		&net.IPNet{IP: net.ParseIP("127.0.0.1"), Mask: net.CIDRMask(8, 32)},
+		&net.IPNet{IP: net.ParseIP("fe80::1"), Mask: net.CIDRMask(64, 128)},
+		&net.IPNet{IP: net.ParseIP("192.168.0.2"), Mask: net.CIDRMask(24, 32)},
+		&net.IPNet{IP: net.ParseIP("fd00::2"), Mask: net.CIDRMask(64, 128)},
+		&net.IPNet{IP: net.ParseIP("198.51.100.2"), Mask: net.CIDRMask(24, 32)},
+	}
+
+	if addr, ok := selectAddr(addrs, ddnsnow.RecordTypeA); !ok || addr != netip.MustParseAddr("198.51.100.2") {
+		t.Fatalf("unexpected A address: %s", addr)
+	}
+	if addr, ok := selectAddr(addrs, ddnsnow.RecordTypeAAAA); !ok || addr != netip.MustParseAddr("fd00::2") {
+		t.Fatalf("unexpected AAAA address: %s", addr)
+	}
+	if addr, ok := selectAddr(addrs[:2], ddnsnow.RecordTypeA); ok {
+		t.Fatalf("unexpected A address: %s", addr)
+	}
+}
+
+func TestJittered(t *testing.T) {
+	for range 100 {
+		if d := jittered(time.Minute, 0.1); d < 54*time.Second || d > 66*time.Second {
+			t.Fatalf("unexpected interval: %s", d)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+data "ddnsnow_records" "all" {}
+
+# Only the TXT records
+data "ddnsnow_records" "txt" {
+  type = "TXT"
+}
+
+# Back up the records as a zone file
+resource "local_file" "zone" {
+  filename = "ddnsnow.zone"
+  content  = data.ddnsnow_records.all.zone_file
+}
@@ -0,0 +1,4 @@
+provider "ddnsnow" {
+  username = "example"
+  password = var.ddnsnow_password
+}
@@ -0,0 +1,2 @@
+# The resource covers the whole domain, so the import ID is always "domain".
+terraform import ddnsnow_domain.this domain
@@ -0,0 +1,6 @@
+resource "ddnsnow_domain" "this" {
+  a        = "127.0.0.1"
+  aaaa     = "::1"
+  txt      = ["v=spf1 -all"]
+  wildcard = true
+}
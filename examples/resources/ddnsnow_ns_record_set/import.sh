@@ -0,0 +1,2 @@
+# The import ID is the record type. Every NS value of the domain is taken over.
+terraform import ddnsnow_ns_record_set.delegation NS
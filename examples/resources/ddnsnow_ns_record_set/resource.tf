@@ -0,0 +1,6 @@
+resource "ddnsnow_ns_record_set" "delegation" {
+  values = [
+    "ns1.example.com",
+    "ns2.example.com",
+  ]
+}
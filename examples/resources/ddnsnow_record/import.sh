@@ -0,0 +1,2 @@
+# Records are imported by their type and value separated by a colon.
+terraform import ddnsnow_record.txt_record TXT:some-value
@@ -0,0 +1,2 @@
+# The import ID is the record type. Every TXT value of the domain is taken over.
+terraform import ddnsnow_txt_record_set.verification TXT
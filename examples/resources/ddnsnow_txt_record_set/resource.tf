@@ -0,0 +1,6 @@
+resource "ddnsnow_txt_record_set" "verification" {
+  values = [
+    "google-site-verification=0123456789abcdef",
+    "v=spf1 -all",
+  ]
+}
@@ -0,0 +1,2 @@
+# The wildcard setting exists once per domain, so the import ID is always "wildcard".
+terraform import ddnsnow_wildcard.this wildcard
@@ -0,0 +1,3 @@
+resource "ddnsnow_wildcard" "this" {
+  enabled = true
+}
@@ -0,0 +1,35 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"errors"
+
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+)
+
+// clientErrorDetail builds the detail of a diagnostic for an error returned
+// by the DDNS Now client, adding guidance for the errors practitioners can
+// act on.
+func clientErrorDetail(message string, err error) string {
+	detail := message + ": " + err.Error()
+
+	var conflictErr *ddnsnow.ConflictError
+	switch {
+	case errors.Is(err, ddnsnow.ErrUnauthorized):
+		detail += "\n\nDDNS Now rejected the credentials. " +
+			"Check the username and password_hash or password values of the provider configuration."
+	case errors.Is(err, ddnsnow.ErrCNAMEConflict):
+		detail += "\n\nA CNAME record cannot coexist with A, AAAA or TXT records. " +
+			"Remove the conflicting records before adding this one."
+	case errors.Is(err, ddnsnow.ErrAlreadyExists):
+		detail += "\n\nThe domain already holds a value for this record type, which allows only one value. " +
+			"Remove the existing value from DDNS Now before adding this one."
+	case errors.As(err, &conflictErr):
+		detail += "\n\nThe domain settings kept changing while they were being updated, e.g. in the DDNS Now web UI. " +
+			"Try again once nobody else is editing them."
+	}
+
+	return detail
+}
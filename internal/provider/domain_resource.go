@@ -0,0 +1,430 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// domainID is the identifier of the domain resource, which exists once per
+// domain.
+const domainID = "domain"
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &domainResource{}
+	_ resource.ResourceWithConfigure      = &domainResource{}
+	_ resource.ResourceWithImportState    = &domainResource{}
+	_ resource.ResourceWithValidateConfig = &domainResource{}
+	_ resource.ResourceWithModifyPlan     = &domainResource{}
+)
+
+// NewDomainResource is a helper function to simplify the provider implementation.
+func NewDomainResource() resource.Resource {
+	return &domainResource{}
+}
+
+// domainResource is the resource implementation.
+type domainResource struct {
+	client         ddnsnow.Client
+	plannedRecords *plannedRecords
+}
+
+// Metadata returns the resource type name.
+func (r *domainResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_domain"
+}
+
+// Schema defines the schema for the resource.
+func (r *domainResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Authoritatively manages every record and the wildcard setting of the domain. " +
+			"Records not declared here are removed, including those added outside of Terraform. " +
+			"Do not combine with other resources of this provider. Destroying the resource removes every record.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The identifier of the resource. Always `" + domainID + "`.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"a": schema.StringAttribute{
+				Description: "The value of the A record.",
+				Optional:    true,
+				Validators: []validator.String{
+					recordValueValidator{recordType: ddnsnow.RecordTypeA},
+				},
+			},
+			"aaaa": schema.StringAttribute{
+				Description: "The value of the AAAA record.",
+				Optional:    true,
+				Validators: []validator.String{
+					recordValueValidator{recordType: ddnsnow.RecordTypeAAAA},
+				},
+			},
+			"cname": schema.StringAttribute{
+				Description: "The value of the CNAME record. Cannot be combined with `a`, `aaaa` and `txt`.",
+				Optional:    true,
+				Validators: []validator.String{
+					recordValueValidator{recordType: ddnsnow.RecordTypeCNAME},
+				},
+			},
+			"ns": schema.SetAttribute{
+				Description: "The values of the NS records.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators: []validator.Set{
+					recordValuesValidator{recordType: ddnsnow.RecordTypeNS},
+				},
+			},
+			"txt": schema.SetAttribute{
+				Description: "The values of the TXT records.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators: []validator.Set{
+					recordValuesValidator{recordType: ddnsnow.RecordTypeTXT},
+				},
+			},
+			"wildcard": schema.BoolAttribute{
+				Description: "Whether subdomains of the domain resolve to the records of the domain. Defaults to false.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+		},
+	}
+}
+
+// ValidateConfig rejects a CNAME record along with A, AAAA or TXT records.
+func (r *domainResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config domainResourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.CNAME.IsNull() {
+		return
+	}
+
+	for _, attr := range []struct {
+		name       string
+		configured bool
+	}{
+		{"a", !config.A.IsNull()},
+		{"aaaa", !config.AAAA.IsNull()},
+		{"txt", !config.TXT.IsNull() && (config.TXT.IsUnknown() || len(config.TXT.Elements()) > 0)},
+	} {
+		if attr.configured {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("cname"),
+				"Conflicting DDNS Now Records",
+				"A CNAME record cannot coexist with A, AAAA or TXT records. Remove either cname or "+attr.name+".",
+			)
+		}
+	}
+}
+
+// ModifyPlan fails the plan when a record cannot coexist with a record
+// planned by another resource.
+func (r *domainResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// The resource is destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan domainResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The sets hold the values of the multi-valued record types
+	singles := map[ddnsnow.RecordType]types.String{
+		ddnsnow.RecordTypeA:     plan.A,
+		ddnsnow.RecordTypeAAAA:  plan.AAAA,
+		ddnsnow.RecordTypeCNAME: plan.CNAME,
+	}
+	sets := map[ddnsnow.RecordType]types.Set{
+		ddnsnow.RecordTypeNS:  plan.NS,
+		ddnsnow.RecordTypeTXT: plan.TXT,
+	}
+	for _, typ := range ddnsnow.RecordTypes {
+		var values []types.String
+		if value, ok := singles[typ]; ok {
+			values = []types.String{value}
+		} else if set := sets[typ]; !set.IsNull() && !set.IsUnknown() {
+			resp.Diagnostics.Append(set.ElementsAs(ctx, &values, false)...)
+		}
+
+		var records []ddnsnow.Record
+		for _, value := range values {
+			if !value.IsNull() && !value.IsUnknown() {
+				records = append(records, ddnsnow.Record{Type: typ, Value: value.ValueString()})
+			}
+		}
+		resp.Diagnostics.Append(r.plannedRecords.keepAll(path.Root(strings.ToLower(string(typ))), records)...)
+	}
+}
+
+// Create creates the resource and sets the initial Terraform state.
+func (r *domainResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan domainResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Submit the desired settings
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *domainResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state domainResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get refreshed settings from DDNS Now
+	settings, err := r.client.GetSettings(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading DDNS Now Domain",
+			clientErrorDetail("Could not read DDNS Now settings", err),
+		)
+		return
+	}
+
+	// Overwrite items with refreshed state
+	resp.Diagnostics.Append(state.fromSettings(ctx, settings)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *domainResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan
+	var plan domainResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Submit the desired settings
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Update resource state with updated settings
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete removes every record of the domain and removes the Terraform state
+// on success.
+func (r *domainResource) Delete(ctx context.Context, _ resource.DeleteRequest, resp *resource.DeleteResponse) {
+	err := r.client.SetSettings(ctx, &ddnsnow.Settings{})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting DDNS Now Domain",
+			clientErrorDetail("Could not remove records", err),
+		)
+		return
+	}
+}
+
+// ImportState adopts the current settings of the domain. The import ID is
+// ignored, as the resource covers the whole domain.
+func (r *domainResource) ImportState(ctx context.Context, _ resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	settings, err := r.client.GetSettings(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Importing DDNS Now Domain",
+			clientErrorDetail("Could not read DDNS Now settings", err),
+		)
+		return
+	}
+
+	state := domainResourceModel{
+		NS:  types.SetNull(types.StringType),
+		TXT: types.SetNull(types.StringType),
+	}
+	resp.Diagnostics.Append(state.fromSettings(ctx, settings)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// apply submits the settings described by plan and populates its computed
+// values.
+func (r *domainResource) apply(ctx context.Context, plan *domainResourceModel, diags *diag.Diagnostics) {
+	settings, d := plan.toSettings(ctx)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+
+	err := r.client.SetSettings(ctx, settings)
+	if err != nil {
+		diags.AddError(
+			"Error Updating DDNS Now Domain",
+			clientErrorDetail("Could not update DDNS Now settings", err),
+		)
+		return
+	}
+
+	plan.ID = types.StringValue(domainID)
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *domainResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(ddnsnow.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected ddnsnow.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	if data, ok := req.ProviderData.(*configuredClient); ok {
+		r.plannedRecords = data.plannedRecords
+	}
+}
+
+// domainResourceModel maps the resource schema data.
+type domainResourceModel struct {
+	ID       types.String `tfsdk:"id"`
+	A        types.String `tfsdk:"a"`
+	AAAA     types.String `tfsdk:"aaaa"`
+	CNAME    types.String `tfsdk:"cname"`
+	NS       types.Set    `tfsdk:"ns"`
+	TXT      types.Set    `tfsdk:"txt"`
+	Wildcard types.Bool   `tfsdk:"wildcard"`
+}
+
+// toSettings converts the model into the settings to submit.
+func (m *domainResourceModel) toSettings(ctx context.Context) (*ddnsnow.Settings, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	settings := &ddnsnow.Settings{
+		Records:        map[ddnsnow.RecordType][]string{},
+		EnableWildcard: m.Wildcard.ValueBool(),
+	}
+
+	for typ, value := range map[ddnsnow.RecordType]types.String{
+		ddnsnow.RecordTypeA:     m.A,
+		ddnsnow.RecordTypeAAAA:  m.AAAA,
+		ddnsnow.RecordTypeCNAME: m.CNAME,
+	} {
+		if value.ValueString() != "" {
+			settings.Records[typ] = []string{value.ValueString()}
+		}
+	}
+
+	for typ, set := range map[ddnsnow.RecordType]types.Set{
+		ddnsnow.RecordTypeNS:  m.NS,
+		ddnsnow.RecordTypeTXT: m.TXT,
+	} {
+		var values []string
+		diags.Append(set.ElementsAs(ctx, &values, false)...)
+		if len(values) > 0 {
+			settings.Records[typ] = values
+		}
+	}
+
+	return settings, diags
+}
+
+// fromSettings overwrites the model with the settings read from DDNS Now.
+// Absent records are represented as null, except for sets which were
+// explicitly configured to be empty.
+func (m *domainResourceModel) fromSettings(ctx context.Context, settings *ddnsnow.Settings) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	m.ID = types.StringValue(domainID)
+
+	single := func(typ ddnsnow.RecordType) types.String {
+		if len(settings.Records[typ]) == 0 {
+			return types.StringNull()
+		}
+		return types.StringValue(settings.Records[typ][0])
+	}
+	m.A = single(ddnsnow.RecordTypeA)
+	m.AAAA = single(ddnsnow.RecordTypeAAAA)
+	m.CNAME = single(ddnsnow.RecordTypeCNAME)
+
+	multiple := func(typ ddnsnow.RecordType, prior types.Set) types.Set {
+		values := settings.Records[typ]
+		if len(values) == 0 && prior.IsNull() {
+			return types.SetNull(types.StringType)
+		}
+		set, d := types.SetValueFrom(ctx, types.StringType, append([]string{}, values...))
+		diags.Append(d...)
+		return set
+	}
+	m.NS = multiple(ddnsnow.RecordTypeNS, m.NS)
+	m.TXT = multiple(ddnsnow.RecordTypeTXT, m.TXT)
+
+	m.Wildcard = types.BoolValue(settings.EnableWildcard)
+
+	return diags
+}
@@ -0,0 +1,134 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"terraform-provider-ddnsnow/pkg/ddnsnow/ddnsnowtest"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccDomainResource(t *testing.T) {
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+
+	expectFields := func(want map[string]string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			for key, value := range want {
+				if testServer.Field(key) != value {
+					return fmt.Errorf("unexpected %s: %q", key, testServer.Field(key))
+				}
+			}
+			return nil
+		}
+	}
+
+	config := fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_domain" "test" {
+  a        = "127.0.0.1"
+  txt      = ["record1", "record2"]
+  wildcard = true
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ddnsnow_domain.test", "id", "domain"),
+					resource.TestCheckResourceAttr("ddnsnow_domain.test", "a", "127.0.0.1"),
+					resource.TestCheckResourceAttr("ddnsnow_domain.test", "txt.#", "2"),
+					resource.TestCheckResourceAttr("ddnsnow_domain.test", "wildcard", "true"),
+					expectFields(map[string]string{
+						"update_data_a":        "127.0.0.1",
+						"update_data_aaaa":     "",
+						"update_data_wildcard": "1",
+					}),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "ddnsnow_domain.test",
+				ImportState:       true,
+				ImportStateId:     "domain",
+				ImportStateVerify: true,
+			},
+			// Out-of-band additions are reverted
+			{
+				PreConfig: func() {
+					testServer.SetField("update_data_aaaa", "::1")
+					testServer.SetField("update_data_txt", "record1\nrecord2\nhuman")
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("ddnsnow_domain.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: expectFields(map[string]string{
+					"update_data_aaaa": "",
+					"update_data_txt":  "record1\nrecord2",
+				}),
+			},
+			// Update and Read testing
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_domain" "test" {
+  cname = "example.com"
+  ns    = ["ns1.example.com"]
+  txt   = []
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("ddnsnow_domain.test", "a"),
+					resource.TestCheckResourceAttr("ddnsnow_domain.test", "cname", "example.com"),
+					resource.TestCheckResourceAttr("ddnsnow_domain.test", "txt.#", "0"),
+					resource.TestCheckResourceAttr("ddnsnow_domain.test", "wildcard", "false"),
+					expectFields(map[string]string{
+						"update_data_a":        "",
+						"update_data_cname":    "example.com",
+						"update_data_ns":       "ns1.example.com",
+						"update_data_txt":      "",
+						"update_data_wildcard": "",
+					}),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+		CheckDestroy: expectFields(map[string]string{
+			"update_data_cname": "",
+			"update_data_ns":    "",
+		}),
+	})
+}
+
+func TestAccDomainResourceRejectsCNAMEConflicts(t *testing.T) {
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_domain" "test" {
+  cname = "example.com"
+  txt   = ["record"]
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Conflicting DDNS Now Records`),
+			},
+		},
+	})
+}
@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// configuredClient is the data shared by the resources of a configured
+// provider.
+type configuredClient struct {
+	ddnsnow.Client
+
+	plannedRecords *plannedRecords
+}
+
+// plannedRecords tracks the records planned by the resources of the provider.
+// Terraform plans every resource of an operation within the same provider
+// process, so conflicts between resources surface before anything changes.
+//
+// Conflicts with the records published on the domain are only warned about,
+// because whether a published record is removed by the operation depends on
+// the order in which Terraform plans the resources, which is not fixed.
+type plannedRecords struct {
+	client ddnsnow.Client
+
+	mu   sync.Mutex
+	kept []ddnsnow.Record
+	// owned holds the records in the prior state of the planned resources,
+	// which account for the records published on the domain.
+	owned     []ddnsnow.Record
+	published *ddnsnow.Settings
+}
+
+// own registers records in the prior state of a planned resource.
+func (p *plannedRecords) own(records []ddnsnow.Record) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, record := range records {
+		if !slices.Contains(p.owned, record) {
+			p.owned = append(p.owned, record)
+		}
+	}
+}
+
+// keep registers a record which exists once the operation completes and
+// returns a previously registered record conflicting with it, if any.
+func (p *plannedRecords) keep(record ddnsnow.Record) (ddnsnow.Record, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, kept := range p.kept {
+		if kept.ConflictsWith(record) {
+			return kept, true
+		}
+	}
+
+	if !slices.Contains(p.kept, record) {
+		p.kept = append(p.kept, record)
+	}
+	return ddnsnow.Record{}, false
+}
+
+// keepAll registers the records planned by a resource, reporting those which
+// conflict with records planned by other resources on attribute.
+func (p *plannedRecords) keepAll(attribute path.Path, records []ddnsnow.Record) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if p == nil {
+		return diags
+	}
+
+	for _, record := range records {
+		conflicting, ok := p.keep(record)
+		if !ok {
+			continue
+		}
+		diags.AddAttributeError(
+			attribute,
+			"Conflicting DDNS Now Records",
+			fmt.Sprintf("The %s record %q cannot coexist with the %s record %q planned by another resource. "+
+				"A CNAME record cannot coexist with A, AAAA or TXT records.",
+				record.Type, record.Value, conflicting.Type, conflicting.Value),
+		)
+	}
+	return diags
+}
+
+// checkPublished warns, on attribute, about records conflicting with records
+// published on the domain which no planned resource accounts for. The
+// published records are read once per provider instance.
+func (p *plannedRecords) checkPublished(ctx context.Context, attribute path.Path, records []ddnsnow.Record) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if p == nil || len(records) == 0 {
+		return diags
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.published == nil {
+		settings, err := p.client.GetSettings(ctx)
+		if err != nil {
+			diags.AddError(
+				"Error Reading DDNS Now Records",
+				clientErrorDetail("Could not read DDNS Now settings to check for conflicting records", err),
+			)
+			return diags
+		}
+		p.published = settings
+	}
+
+	for _, record := range records {
+		for _, typ := range ddnsnow.RecordTypes {
+			for _, value := range p.published.Records[typ] {
+				existing := ddnsnow.Record{Type: typ, Value: value}
+				if !existing.ConflictsWith(record) || slices.Contains(p.owned, existing) {
+					continue
+				}
+				diags.AddAttributeWarning(
+					attribute,
+					"Conflicting DDNS Now Records",
+					fmt.Sprintf("The %s record %q cannot coexist with the %s record %q published on the domain, "+
+						"which no resource of this configuration manages. "+
+						"A CNAME record cannot coexist with A, AAAA or TXT records, so applying fails unless the published record is removed first.",
+						record.Type, record.Value, existing.Type, existing.Value),
+				)
+			}
+		}
+	}
+	return diags
+}
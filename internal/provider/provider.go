@@ -5,10 +5,14 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"time"
 
 	"terraform-provider-ddnsnow/pkg/ddnsnow"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
@@ -40,9 +44,23 @@ type ddnsnowProvider struct {
 
 // ddnsnowProviderModel maps provider schema data to a Go type.
 type ddnsnowProviderModel struct {
-	Username     types.String `tfsdk:"username"`
-	PasswordHash types.String `tfsdk:"password_hash"`
-	Server       types.String `tfsdk:"server"`
+	Username          types.String  `tfsdk:"username"`
+	PasswordHash      types.String  `tfsdk:"password_hash"`
+	Password          types.String  `tfsdk:"password"`
+	Server            types.String  `tfsdk:"server"`
+	Retry             *retryModel   `tfsdk:"retry"`
+	RequestsPerSecond types.Float64 `tfsdk:"requests_per_second"`
+	Burst             types.Int64   `tfsdk:"burst"`
+}
+
+// retryModel maps the retry block of the provider schema.
+type retryModel struct {
+	MaxAttempts          types.Int64  `tfsdk:"max_attempts"`
+	BaseBackoff          types.String `tfsdk:"base_backoff"`
+	MaxBackoff           types.String `tfsdk:"max_backoff"`
+	Jitter               types.Bool   `tfsdk:"jitter"`
+	RetryableStatusCodes types.List   `tfsdk:"retryable_status_codes"`
+	RetryableErrorCodes  types.List   `tfsdk:"retryable_error_codes"`
 }
 
 // Metadata returns the provider type name.
@@ -56,18 +74,63 @@ func (p *ddnsnowProvider) Schema(_ context.Context, _ provider.SchemaRequest, re
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"username": schema.StringAttribute{
-				Description: "The DDNS Now username. Also known as a subdomain of 'f5.si'.",
+				Description: "The DDNS Now username. Also known as a subdomain of 'f5.si'. May also be provided via the DDNSNOW_USERNAME environment variable.",
 				Optional:    true,
 			},
 			"password_hash": schema.StringAttribute{
-				Description: "The DDNS Now password hash. This is contained inside the cookie_loginuser key in the HTTP Cookie.",
+				Description: "The DDNS Now password hash. This is contained inside the cookie_loginuser key in the HTTP Cookie. Conflicts with password. May also be provided via the DDNSNOW_PASSWORD_HASH environment variable.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"password": schema.StringAttribute{
+				Description: "The DDNS Now password. The provider logs in with it, and logs in again whenever the session expires. Conflicts with password_hash. May also be provided via the DDNSNOW_PASSWORD environment variable.",
 				Optional:    true,
 				Sensitive:   true,
 			},
 			"server": schema.StringAttribute{
-				Description: "The domain of the DDNS Now server. Defaults to 'f5.si'. This attribute is used for testing purposes.",
+				Description: "The domain of the DDNS Now server. Defaults to 'f5.si'. This attribute is used for testing purposes. May also be provided via the DDNSNOW_SERVER environment variable.",
+				Optional:    true,
+			},
+			"requests_per_second": schema.Float64Attribute{
+				Description: "The maximum average number of requests per second sent to DDNS Now, shared by all resources and data sources of the provider. Defaults to no limit.",
 				Optional:    true,
 			},
+			"burst": schema.Int64Attribute{
+				Description: "The number of requests which may be sent at once before requests_per_second applies. Defaults to 1.",
+				Optional:    true,
+			},
+			"retry": schema.SingleNestedAttribute{
+				Description: "How requests failing with transient errors are retried. Only requests which are safe to send again are retried.",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"max_attempts": schema.Int64Attribute{
+						Description: "The maximum number of attempts per request including the first one. Defaults to 4. Set to 1 to disable retries.",
+						Optional:    true,
+					},
+					"base_backoff": schema.StringAttribute{
+						Description: "The wait before the first retry, doubling on every further retry. Defaults to '1s'.",
+						Optional:    true,
+					},
+					"max_backoff": schema.StringAttribute{
+						Description: "The upper bound of the wait between retries. Defaults to '30s'.",
+						Optional:    true,
+					},
+					"jitter": schema.BoolAttribute{
+						Description: "Whether to randomize each wait between half and all of its length. Defaults to true.",
+						Optional:    true,
+					},
+					"retryable_status_codes": schema.ListAttribute{
+						Description: "The HTTP status codes to retry. Defaults to 429, 500, 502, 503 and 504.",
+						ElementType: types.Int64Type,
+						Optional:    true,
+					},
+					"retryable_error_codes": schema.ListAttribute{
+						Description: "The DDNS Now error codes to retry. Defaults to none.",
+						ElementType: types.Int64Type,
+						Optional:    true,
+					},
+				},
+			},
 		},
 	}
 }
@@ -90,7 +153,7 @@ func (p *ddnsnowProvider) Configure(ctx context.Context, req provider.ConfigureR
 			path.Root("username"),
 			"Unknown DDNS Now API Username",
 			"The provider cannot create the DDNS Now API client as there is an unknown configuration value for the DDNS Now username. "+
-				"Target apply the source of the value first, set the value statically in the configuration.",
+				"Target apply the source of the value first, set the value statically in the configuration, or use the DDNSNOW_USERNAME environment variable.",
 		)
 	}
 
@@ -99,7 +162,25 @@ func (p *ddnsnowProvider) Configure(ctx context.Context, req provider.ConfigureR
 			path.Root("password_hash"),
 			"Unknown DDNS Now Password Hash",
 			"The provider cannot create the DDNS Now API client as there is an unknown configuration value for the DDNS Now Password Hash. "+
-				"Target apply the source of the value first, set the value statically in the configuration.",
+				"Target apply the source of the value first, set the value statically in the configuration, or use the DDNSNOW_PASSWORD_HASH environment variable.",
+		)
+	}
+
+	if config.Password.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("password"),
+			"Unknown DDNS Now Password",
+			"The provider cannot create the DDNS Now API client as there is an unknown configuration value for the DDNS Now Password. "+
+				"Target apply the source of the value first, set the value statically in the configuration, or use the DDNSNOW_PASSWORD environment variable.",
+		)
+	}
+
+	if config.Server.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("server"),
+			"Unknown DDNS Now Server",
+			"The provider cannot create the DDNS Now API client as there is an unknown configuration value for the DDNS Now server. "+
+				"Target apply the source of the value first, set the value statically in the configuration, or use the DDNSNOW_SERVER environment variable.",
 		)
 	}
 
@@ -107,14 +188,23 @@ func (p *ddnsnowProvider) Configure(ctx context.Context, req provider.ConfigureR
 		return
 	}
 
-	var username, passwordHash, server string
+	// Default values to environment variables, but override
+	// with Terraform configuration value if set.
+
+	username := os.Getenv("DDNSNOW_USERNAME")
+	passwordHash := os.Getenv("DDNSNOW_PASSWORD_HASH")
+	password := os.Getenv("DDNSNOW_PASSWORD")
+	server := os.Getenv("DDNSNOW_SERVER")
 
 	if !config.Username.IsNull() {
 		username = config.Username.ValueString()
 	}
 
-	if !config.PasswordHash.IsNull() {
+	// The password hash and the password are alternatives, so configuring
+	// either of them overrides both environment variables.
+	if !config.PasswordHash.IsNull() || !config.Password.IsNull() {
 		passwordHash = config.PasswordHash.ValueString()
+		password = config.Password.ValueString()
 	}
 
 	if !config.Server.IsNull() {
@@ -129,27 +219,71 @@ func (p *ddnsnowProvider) Configure(ctx context.Context, req provider.ConfigureR
 			path.Root("username"),
 			"Missing DDNS Now Username",
 			"The provider cannot create the DDNS Now API client as there is a missing or empty value for the DDNS Now username. "+
-				"Set the username value in the configuration. "+
+				"Set the username value in the configuration or use the DDNSNOW_USERNAME environment variable. "+
 				"If this is already set, ensure the value is not empty.",
 		)
 	}
 
-	if passwordHash == "" {
+	if passwordHash == "" && password == "" {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("password_hash"),
 			"Missing DDNS Now Password Hash",
 			"The provider cannot create the DDNS Now API client as there is a missing or empty value for the DDNS Now Password Hash. "+
-				"Set the password_hash value in the configuration. "+
+				"Set the password_hash or password value in the configuration or use the DDNSNOW_PASSWORD_HASH or DDNSNOW_PASSWORD environment variable. "+
 				"If this is already set, ensure the value is not empty.",
 		)
 	}
 
+	if passwordHash != "" && password != "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("password"),
+			"Conflicting DDNS Now Credentials",
+			"The provider cannot create the DDNS Now API client as both a DDNS Now Password Hash and a Password are set. "+
+				"Set only one of the password_hash and password values.",
+		)
+	}
+
+	retryPolicy, diags := config.Retry.policy(ctx)
+	resp.Diagnostics.Append(diags...)
+
+	var requestsPerSecond float64
+	burst := int64(1)
+
+	if !config.RequestsPerSecond.IsNull() {
+		requestsPerSecond = config.RequestsPerSecond.ValueFloat64()
+		if config.RequestsPerSecond.IsUnknown() || requestsPerSecond <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("requests_per_second"),
+				"Invalid Requests Per Second",
+				"The requests_per_second value must be a known, positive number.",
+			)
+		}
+	}
+
+	if !config.Burst.IsNull() {
+		burst = config.Burst.ValueInt64()
+		if config.Burst.IsUnknown() || burst < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("burst"),
+				"Invalid Burst",
+				"The burst value must be a known number of at least 1.",
+			)
+		}
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	// Create a new DDNS Now client using the configuration values
-	client, err := ddnsnow.NewClient(&username, &passwordHash, &server)
+	opts := []ddnsnow.ClientOption{
+		ddnsnow.WithRetryPolicy(retryPolicy),
+		ddnsnow.WithRateLimit(requestsPerSecond, int(burst)),
+	}
+	if password != "" {
+		opts = append(opts, ddnsnow.WithPassword(password))
+	}
+	client, err := ddnsnow.NewClient(&username, &passwordHash, &server, opts...)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to Create DDNS Now API Client",
@@ -163,17 +297,101 @@ func (p *ddnsnowProvider) Configure(ctx context.Context, req provider.ConfigureR
 	// Make the DDNS Now client available during DataSource and Resource
 	// type Configure methods.
 	resp.DataSourceData = client
-	resp.ResourceData = client
+	resp.ResourceData = &configuredClient{
+		Client:         client,
+		plannedRecords: &plannedRecords{client: client},
+	}
+}
+
+// policy converts the retry block into a retry policy, falling back to the
+// defaults for anything not configured.
+func (m *retryModel) policy(ctx context.Context) (ddnsnow.RetryPolicy, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	policy := ddnsnow.DefaultRetryPolicy()
+	if m == nil {
+		return policy, diags
+	}
+
+	if !m.MaxAttempts.IsNull() {
+		if m.MaxAttempts.IsUnknown() || m.MaxAttempts.ValueInt64() < 1 {
+			diags.AddAttributeError(
+				path.Root("retry").AtName("max_attempts"),
+				"Invalid Retry Max Attempts",
+				"The max_attempts value must be a known number of at least 1.",
+			)
+		}
+		policy.MaxAttempts = int(m.MaxAttempts.ValueInt64())
+	}
+
+	for name, attr := range map[string]struct {
+		value  types.String
+		target *time.Duration
+	}{
+		"base_backoff": {m.BaseBackoff, &policy.BaseBackoff},
+		"max_backoff":  {m.MaxBackoff, &policy.MaxBackoff},
+	} {
+		if attr.value.IsNull() {
+			continue
+		}
+		d, err := time.ParseDuration(attr.value.ValueString())
+		if attr.value.IsUnknown() || err != nil || d < 0 {
+			diags.AddAttributeError(
+				path.Root("retry").AtName(name),
+				"Invalid Retry Backoff",
+				fmt.Sprintf("The %s value must be a known, non-negative duration such as '500ms' or '2s'.", name),
+			)
+			continue
+		}
+		*attr.target = d
+	}
+
+	if !m.Jitter.IsNull() {
+		policy.Jitter = m.Jitter.ValueBool()
+	}
+
+	for name, attr := range map[string]struct {
+		value  types.List
+		target *[]int
+	}{
+		"retryable_status_codes": {m.RetryableStatusCodes, &policy.RetryableStatusCodes},
+		"retryable_error_codes":  {m.RetryableErrorCodes, &policy.RetryableErrorCodes},
+	} {
+		if attr.value.IsNull() {
+			continue
+		}
+		if attr.value.IsUnknown() {
+			diags.AddAttributeError(
+				path.Root("retry").AtName(name),
+				"Unknown Retryable Codes",
+				fmt.Sprintf("The %s value must be known when configuring the provider.", name),
+			)
+			continue
+		}
+		var codes []int64
+		diags.Append(attr.value.ElementsAs(ctx, &codes, false)...)
+		*attr.target = make([]int, 0, len(codes))
+		for _, code := range codes {
+			*attr.target = append(*attr.target, int(code))
+		}
+	}
+
+	return policy, diags
 }
 
 // DataSources defines the data sources implemented in the provider.
 func (p *ddnsnowProvider) DataSources(_ context.Context) []func() datasource.DataSource {
-	return nil
+	return []func() datasource.DataSource{
+		NewRecordsDataSource,
+	}
 }
 
 // Resources defines the resources implemented in the provider.
 func (p *ddnsnowProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewRecordResource,
+		NewWildcardResource,
+		NewDomainResource,
+		NewTXTRecordSetResource,
+		NewNSRecordSetResource,
 	}
 }
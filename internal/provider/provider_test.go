@@ -4,8 +4,16 @@
 package provider
 
 import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+	"terraform-provider-ddnsnow/pkg/ddnsnow/ddnsnowtest"
+
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
 const (
@@ -29,3 +37,142 @@ var (
 		"ddnsnow": providerserver.NewProtocol6WithError(New("test")()),
 	}
 )
+
+// newCredentialsTestServer returns an emulated DDNS Now control page
+// accepting username with passwordHash or password, and holding an A record.
+func newCredentialsTestServer(username, passwordHash, password string) *ddnsnowtest.Server {
+	testServer := ddnsnowtest.NewServer(
+		ddnsnowtest.WithCredentials(username, passwordHash),
+		ddnsnowtest.WithPassword(password),
+	)
+	testServer.SetRecords(ddnsnow.RecordTypeA, "127.0.0.1")
+	return testServer
+}
+
+const testAccRecordsDataSourceConfig = `
+data "ddnsnow_records" "test" {}
+`
+
+func TestAccProviderConfiguresFromEnvironment(t *testing.T) {
+	testServer := newCredentialsTestServer("env", "fedcba9876543210fedcba9876543210", "secret")
+	defer testServer.Close()
+
+	t.Setenv("DDNSNOW_USERNAME", "env")
+	t.Setenv("DDNSNOW_PASSWORD_HASH", "fedcba9876543210fedcba9876543210")
+	t.Setenv("DDNSNOW_PASSWORD", "")
+	t.Setenv("DDNSNOW_SERVER", testServer.URL)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `provider "ddnsnow" {}` + testAccRecordsDataSourceConfig,
+				Check:  resource.TestCheckResourceAttr("data.ddnsnow_records.test", "a", "127.0.0.1"),
+			},
+		},
+	})
+}
+
+func TestAccProviderConfigurationOverridesEnvironment(t *testing.T) {
+	testServer := newCredentialsTestServer("domain", "0123456789abcdef0123456789abcdef", "secret")
+	defer testServer.Close()
+
+	t.Setenv("DDNSNOW_USERNAME", "env")
+	t.Setenv("DDNSNOW_PASSWORD_HASH", "fedcba9876543210fedcba9876543210")
+	t.Setenv("DDNSNOW_PASSWORD", "wrong")
+	t.Setenv("DDNSNOW_SERVER", "http://127.0.0.1:0")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + testAccRecordsDataSourceConfig,
+				Check:  resource.TestCheckResourceAttr("data.ddnsnow_records.test", "a", "127.0.0.1"),
+			},
+		},
+	})
+}
+
+func TestAccProviderRequiresCredentials(t *testing.T) {
+	t.Setenv("DDNSNOW_USERNAME", "")
+	t.Setenv("DDNSNOW_PASSWORD_HASH", "")
+	t.Setenv("DDNSNOW_PASSWORD", "")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      `provider "ddnsnow" {}` + testAccRecordsDataSourceConfig,
+				ExpectError: regexp.MustCompile(`Missing DDNS Now Username`),
+			},
+		},
+	})
+}
+
+func TestAccProviderLogsInWithPassword(t *testing.T) {
+	testServer := newCredentialsTestServer("domain", "0123456789abcdef0123456789abcdef", "secret")
+	defer testServer.Close()
+
+	t.Setenv("DDNSNOW_PASSWORD_HASH", "")
+	t.Setenv("DDNSNOW_PASSWORD", "")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "ddnsnow" {
+  username = "domain"
+  password = "secret"
+  server   = "%s"
+}
+`, testServer.URL) + testAccRecordsDataSourceConfig,
+				Check: resource.TestCheckResourceAttr("data.ddnsnow_records.test", "a", "127.0.0.1"),
+			},
+			{
+				Config: fmt.Sprintf(`
+provider "ddnsnow" {
+  username = "domain"
+  password = "wrong"
+  server   = "%s"
+}
+`, testServer.URL) + testAccRecordsDataSourceConfig,
+				ExpectError: regexp.MustCompile(`Unable to Read DDNS Now Records`),
+			},
+		},
+	})
+}
+
+func TestAccProviderRejectsConflictingCredentials(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "ddnsnow" {
+  username      = "domain"
+  password_hash = "0123456789abcdef0123456789abcdef"
+  password      = "secret"
+}
+` + testAccRecordsDataSourceConfig,
+				ExpectError: regexp.MustCompile(`Conflicting DDNS Now Credentials`),
+			},
+		},
+	})
+}
+
+func TestAccProviderReportsLoginPageAsUnauthorized(t *testing.T) {
+	// The control page shows the login page to unknown credentials
+	testServer := newCredentialsTestServer("other", "fedcba9876543210fedcba9876543210", "secret")
+	defer testServer.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(providerConfigTpl, testServer.URL) + testAccRecordsDataSourceConfig,
+				ExpectError: regexp.MustCompile(`ddnsnow: unauthorized`),
+			},
+		},
+	})
+}
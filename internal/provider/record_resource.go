@@ -5,18 +5,29 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"terraform-provider-ddnsnow/pkg/ddnsnow"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &recordResource{}
-	_ resource.ResourceWithConfigure = &recordResource{}
+	_ resource.Resource                   = &recordResource{}
+	_ resource.ResourceWithConfigure      = &recordResource{}
+	_ resource.ResourceWithImportState    = &recordResource{}
+	_ resource.ResourceWithValidateConfig = &recordResource{}
+	_ resource.ResourceWithModifyPlan     = &recordResource{}
 )
 
 // NewRecordResource is a helper function to simplify the provider implementation.
@@ -26,7 +37,8 @@ func NewRecordResource() resource.Resource {
 
 // recordResource is the resource implementation.
 type recordResource struct {
-	client ddnsnow.Client
+	client         ddnsnow.Client
+	plannedRecords *plannedRecords
 }
 
 // Metadata returns the resource type name.
@@ -37,10 +49,25 @@ func (r *recordResource) Metadata(_ context.Context, req resource.MetadataReques
 // Schema defines the schema for the resource.
 func (r *recordResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Description: "Manages a single record of the domain. A CNAME record planned along with A, AAAA or TXT records " +
+			"by resources of this provider fails the plan. Conflicts with records published outside of the configuration " +
+			"are warned about when planning and fail when applying.",
 		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The identifier of the record in the form `TYPE:VALUE`.",
+				Computed:    true,
+			},
 			"type": schema.StringAttribute{
-				Description: "The record type. One of: `A`, `AAAA`, `CNAME`, `TXT`, `NS`.",
-				Required:    true,
+				Description: "The record type. One of: `A`, `AAAA`, `CNAME`, `TXT`, `NS`. " +
+					"Changing the type replaces the record. Do not use `create_before_destroy` when replacing " +
+					"a CNAME record with an A, AAAA or TXT record or vice versa, as they cannot coexist.",
+				Required: true,
+				Validators: []validator.String{
+					recordTypeValidator{},
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"value": schema.StringAttribute{
 				Description: "The record value.",
@@ -50,6 +77,74 @@ func (r *recordResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 	}
 }
 
+// ValidateConfig validates the value against the record type.
+func (r *recordResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config recordResourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The type itself is validated by its attribute validator
+	recordType := ddnsnow.RecordType(config.Type.ValueString())
+	if config.Type.IsUnknown() || !slices.Contains(ddnsnow.RecordTypes, recordType) {
+		return
+	}
+
+	resp.Diagnostics.Append(validateRecordValue(path.Root("value"), recordType, config.Value)...)
+}
+
+// ModifyPlan fails the plan when the record cannot coexist with a record
+// planned by another resource, and warns when it cannot coexist with a record
+// published on the domain.
+func (r *recordResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var prior *ddnsnow.Record
+	if !req.State.Raw.IsNull() {
+		var state recordResourceModel
+		diags := req.State.Get(ctx, &state)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		prior = &ddnsnow.Record{
+			Type:  ddnsnow.RecordType(state.Type.ValueString()),
+			Value: state.Value.ValueString(),
+		}
+		r.plannedRecords.own([]ddnsnow.Record{*prior})
+	}
+
+	// The resource is destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan recordResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.Type.IsUnknown() || plan.Value.IsUnknown() {
+		return
+	}
+	record := ddnsnow.Record{
+		Type:  ddnsnow.RecordType(plan.Type.ValueString()),
+		Value: plan.Value.ValueString(),
+	}
+
+	resp.Diagnostics.Append(r.plannedRecords.keepAll(path.Root("type"), []ddnsnow.Record{record})...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Unchanged records have been checked when they were planned
+	if prior != nil && *prior == record {
+		return
+	}
+	resp.Diagnostics.Append(r.plannedRecords.checkPublished(ctx, path.Root("type"), []ddnsnow.Record{record})...)
+}
+
 // Create creates the resource and sets the initial Terraform state.
 func (r *recordResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	// Retrieve values from plan
@@ -67,16 +162,17 @@ func (r *recordResource) Create(ctx context.Context, req resource.CreateRequest,
 	}
 
 	// Create new record
-	err := r.client.CreateRecord(record)
+	err := r.client.CreateRecord(ctx, record)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error creating record",
-			"Could not create record, unexpected error: "+err.Error(),
+			clientErrorDetail("Could not create record", err),
 		)
 		return
 	}
 
 	// Map response body to schema and populate Computed attribute values
+	plan.ID = types.StringValue(recordID(record))
 	plan.Type = types.StringValue(string(record.Type))
 	plan.Value = types.StringValue(record.Value)
 
@@ -99,19 +195,25 @@ func (r *recordResource) Read(ctx context.Context, req resource.ReadRequest, res
 	}
 
 	// Get refreshed record value from DDNS Now
-	record, err := r.client.GetRecord(ddnsnow.Record{
+	record, err := r.client.GetRecord(ctx, ddnsnow.Record{
 		Type:  ddnsnow.RecordType(state.Type.ValueString()),
 		Value: state.Value.ValueString(),
 	})
+	// Let Terraform propose to recreate a record removed outside of it
+	if errors.Is(err, ddnsnow.ErrNotFound) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading DDNS Now Record",
-			"Could not read DDNS Now record type "+state.Type.ValueString()+": "+err.Error(),
+			clientErrorDetail("Could not read DDNS Now record type "+state.Type.ValueString(), err),
 		)
 		return
 	}
 
 	// Overwrite items with refreshed state
+	state.ID = types.StringValue(recordID(record))
 	state.Type = types.StringValue(string(record.Type))
 	state.Value = types.StringValue(record.Value)
 
@@ -152,27 +254,28 @@ func (r *recordResource) Update(ctx context.Context, req resource.UpdateRequest,
 	}
 
 	// Update existing record
-	err := r.client.UpdateRecord(oldRecord, newRecord)
+	err := r.client.UpdateRecord(ctx, oldRecord, newRecord)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Updating DDNS Now Record",
-			"Could not update record, unexpected error: "+err.Error(),
+			clientErrorDetail("Could not update record", err),
 		)
 		return
 	}
 
 	// Fetch updated items from GetRecord as UpdateRecord items are not
 	// populated.
-	_, err = r.client.GetRecord(newRecord)
+	_, err = r.client.GetRecord(ctx, newRecord)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading DDNS Now Record",
-			"Could not read DDNS Now record type "+plan.Type.ValueString()+": "+err.Error(),
+			clientErrorDetail("Could not read DDNS Now record type "+plan.Type.ValueString(), err),
 		)
 		return
 	}
 
 	// Update resource state with updated record
+	plan.ID = types.StringValue(recordID(newRecord))
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -191,14 +294,18 @@ func (r *recordResource) Delete(ctx context.Context, req resource.DeleteRequest,
 	}
 
 	// Delete existing record
-	err := r.client.DeleteRecord(ddnsnow.Record{
+	err := r.client.DeleteRecord(ctx, ddnsnow.Record{
 		Type:  ddnsnow.RecordType(state.Type.ValueString()),
 		Value: state.Value.ValueString(),
 	})
+	// A record which is already gone needs no deletion
+	if errors.Is(err, ddnsnow.ErrNotFound) {
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Deleting DDNS Now Record",
-			"Could not delete record, unexpected error: "+err.Error(),
+			clientErrorDetail("Could not delete record", err),
 		)
 		return
 	}
@@ -224,10 +331,68 @@ func (r *recordResource) Configure(_ context.Context, req resource.ConfigureRequ
 	}
 
 	r.client = client
+
+	if data, ok := req.ProviderData.(*configuredClient); ok {
+		r.plannedRecords = data.plannedRecords
+	}
+}
+
+// ImportState imports an existing record by an identifier in the form
+// `TYPE:VALUE`.
+func (r *recordResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	record, err := parseRecordID(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			"Could not parse import ID "+strconv.Quote(req.ID)+": "+err.Error(),
+		)
+		return
+	}
+
+	// Make sure the record exists before adopting it
+	existing, err := r.client.GetRecord(ctx, record)
+	if err == nil && existing.Value != record.Value {
+		err = fmt.Errorf("%w: %s record holds %q", ddnsnow.ErrNotFound, record.Type, existing.Value)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Importing DDNS Now Record",
+			clientErrorDetail("Could not read DDNS Now record "+req.ID, err),
+		)
+		return
+	}
+
+	state := recordResourceModel{
+		ID:    types.StringValue(recordID(record)),
+		Type:  types.StringValue(string(record.Type)),
+		Value: types.StringValue(record.Value),
+	}
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
 }
 
 // recordResourceModel maps the resource schema data.
 type recordResourceModel struct {
+	ID    types.String `tfsdk:"id"`
 	Type  types.String `tfsdk:"type"`
 	Value types.String `tfsdk:"value"`
 }
+
+// recordID returns the identifier of a record, which is also accepted by
+// ImportState.
+func recordID(record ddnsnow.Record) string {
+	return string(record.Type) + ":" + record.Value
+}
+
+// parseRecordID parses an identifier returned by recordID.
+func parseRecordID(id string) (ddnsnow.Record, error) {
+	typ, value, ok := strings.Cut(id, ":")
+	if !ok || typ == "" || value == "" {
+		return ddnsnow.Record{}, fmt.Errorf("expected TYPE:VALUE, e.g. TXT:some-value")
+	}
+
+	return ddnsnow.Record{
+		Type:  ddnsnow.RecordType(typ),
+		Value: value,
+	}, nil
+}
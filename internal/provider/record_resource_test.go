@@ -7,24 +7,20 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"sync/atomic"
 	"testing"
 
+	"terraform-provider-ddnsnow/pkg/ddnsnow/ddnsnowtest"
+
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 func TestAccRecordResource(t *testing.T) {
-	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			if _, err := w.Write([]byte(`<html><textarea id="update_data_txt">dummy</textarea></html>`)); err != nil {
-				t.Fatalf("Write: %v", err)
-			}
-		case http.MethodPost:
-			if _, err := w.Write([]byte(`{"result":"OK"}`)); err != nil {
-				t.Fatalf("Write: %v", err)
-			}
-		}
-	}))
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
 
 	resource.Test(t, resource.TestCase{
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
@@ -38,10 +34,18 @@ resource "ddnsnow_record" "test" {
 }
 `,
 				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ddnsnow_record.test", "id", "TXT:dummy"),
 					resource.TestCheckResourceAttr("ddnsnow_record.test", "type", "TXT"),
 					resource.TestCheckResourceAttr("ddnsnow_record.test", "value", "dummy"),
 				),
 			},
+			// ImportState testing
+			{
+				ResourceName:      "ddnsnow_record.test",
+				ImportState:       true,
+				ImportStateId:     "TXT:dummy",
+				ImportStateVerify: true,
+			},
 			// Update and Read testing
 			{
 				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
@@ -59,3 +63,393 @@ resource "ddnsnow_record" "test" {
 		},
 	})
 }
+
+func TestAccRecordResourceRetriesTransientErrors(t *testing.T) {
+	var requests atomic.Int64
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail every other request to make sure each of them is retried.
+		if requests.Add(1)%2 == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		testServer.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "ddnsnow" {
+  username      = "domain"
+  password_hash = "0123456789abcdef0123456789abcdef"
+  server        = "%s"
+
+  retry = {
+    max_attempts           = 2
+    base_backoff           = "1ms"
+    max_backoff            = "1ms"
+    retryable_status_codes = [502]
+  }
+}
+
+resource "ddnsnow_record" "test" {
+  type  = "TXT"
+  value = "dummy"
+}
+`, proxy.URL),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ddnsnow_record.test", "type", "TXT"),
+					resource.TestCheckResourceAttr("ddnsnow_record.test", "value", "dummy"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccRecordResourceRecreatesRecordRemovedOutOfBand(t *testing.T) {
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+
+	config := fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_record" "test" {
+  type  = "TXT"
+  value = "dummy"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ddnsnow_record.test", "type", "TXT"),
+					resource.TestCheckResourceAttr("ddnsnow_record.test", "value", "dummy"),
+				),
+			},
+			// Remove the record as if it were deleted in the web UI
+			{
+				PreConfig: func() {
+					testServer.SetField("update_data_txt", "")
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("ddnsnow_record.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: func(*terraform.State) error {
+					if testServer.Field("update_data_txt") != "dummy" {
+						return fmt.Errorf("record was not recreated: %q", testServer.Field("update_data_txt"))
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccRecordResourceKeepsStateOnReadErrors(t *testing.T) {
+	var unauthorized atomic.Bool
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if unauthorized.Load() {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		testServer.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+
+	config := fmt.Sprintf(providerConfigTpl, proxy.URL) + `
+resource "ddnsnow_record" "test" {
+  type  = "TXT"
+  value = "dummy"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			// Errors other than a missing record must not drop the record
+			{
+				PreConfig: func() {
+					unauthorized.Store(true)
+				},
+				Config:      config,
+				ExpectError: regexp.MustCompile(`DDNS Now rejected the credentials`),
+			},
+			{
+				PreConfig: func() {
+					unauthorized.Store(false)
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestAccRecordResourceImport(t *testing.T) {
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	testServer.SetField("update_data_aaaa", "::1")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_record" "test" {
+  type  = "AAAA"
+  value = "::2"
+}
+`,
+				ResourceName:  "ddnsnow_record.test",
+				ImportState:   true,
+				ImportStateId: "AAAA:::2",
+				ExpectError:   regexp.MustCompile(`AAAA record holds "::1"`),
+			},
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_record" "test" {
+  type  = "AAAA"
+  value = "::1"
+}
+`,
+				ResourceName:       "ddnsnow_record.test",
+				ImportState:        true,
+				ImportStateId:      "AAAA:::1",
+				ImportStatePersist: true,
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 || states[0].Attributes["value"] != "::1" {
+						return fmt.Errorf("unexpected import result: %v", states)
+					}
+					return nil
+				},
+			},
+			// The imported record is managed as if it were created by Terraform
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_record" "test" {
+  type  = "AAAA"
+  value = "::1"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				Check: func(*terraform.State) error {
+					if testServer.Field("update_data_aaaa") != "::1" {
+						return fmt.Errorf("unexpected record: %q", testServer.Field("update_data_aaaa"))
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccRecordResourceValidation(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s", r.Method)
+	}))
+	defer testServer.Close()
+
+	tests := map[string]struct {
+		typ, value string
+		error      string
+	}{
+		"unsupported type": {"MX", "mail.example.com", `Invalid Record Type`},
+		"invalid A":        {"A", "::1", `Invalid A Record Value`},
+		"invalid AAAA":     {"AAAA", "127.0.0.1", `Invalid AAAA Record Value`},
+		"invalid CNAME":    {"CNAME", "exa_mple.com", `Invalid CNAME Record Value`},
+		"invalid TXT":      {"TXT", "line1\\nline2", `Invalid TXT Record Value`},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resource.Test(t, resource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + fmt.Sprintf(`
+resource "ddnsnow_record" "test" {
+  type  = %q
+  value = "%s"
+}
+`, tt.typ, tt.value),
+						PlanOnly:    true,
+						ExpectError: regexp.MustCompile(regexp.QuoteMeta(tt.error)),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestAccRecordResourceDetectsConflictsAtPlanTime(t *testing.T) {
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	testServer.SetField("update_data_txt", "published")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Conflicts within the configuration
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_record" "a" {
+  type  = "A"
+  value = "127.0.0.1"
+}
+
+resource "ddnsnow_record" "cname" {
+  type  = "CNAME"
+  value = "example.com"
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Conflicting DDNS Now Records`),
+			},
+			// Conflicts with record sets within the configuration
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_txt_record_set" "txt" {
+  values = ["record"]
+}
+
+resource "ddnsnow_record" "cname" {
+  type  = "CNAME"
+  value = "example.com"
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Conflicting DDNS Now Records`),
+			},
+			// Conflicts with the published records are warned about when
+			// planning and fail when applying
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_record" "cname" {
+  type  = "CNAME"
+  value = "example.com"
+}
+`,
+				ExpectError: regexp.MustCompile(`Error creating record`),
+			},
+			// Nothing has been changed
+			{
+				PreConfig: func() {
+					if testServer.Field("update_data_cname") != "" || testServer.Field("update_data_a") != "" {
+						t.Errorf("unexpected change: %q, %q", testServer.Field("update_data_cname"), testServer.Field("update_data_a"))
+					}
+				},
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_record" "ns" {
+  type  = "NS"
+  value = "ns1.example.com"
+}
+`,
+			},
+		},
+	})
+}
+
+func TestAccRecordResourcePlansSwappingConflictingResources(t *testing.T) {
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_record" "a" {
+  type  = "A"
+  value = "127.0.0.1"
+}
+`,
+			},
+			// The published A record is removed by the same operation, so
+			// the plan must not depend on the order of planning.
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_record" "cname" {
+  type  = "CNAME"
+  value = "example.com"
+}
+`,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccRecordResourceReplacesOnTypeChange(t *testing.T) {
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+
+	expectFields := func(want map[string]string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			for key, value := range want {
+				if testServer.Field(key) != value {
+					return fmt.Errorf("unexpected %s: %q", key, testServer.Field(key))
+				}
+			}
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_record" "test" {
+  type  = "A"
+  value = "127.0.0.1"
+}
+`,
+				Check: expectFields(map[string]string{
+					"update_data_a": "127.0.0.1",
+				}),
+			},
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_record" "test" {
+  type  = "CNAME"
+  value = "example.com"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("ddnsnow_record.test", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ddnsnow_record.test", "id", "CNAME:example.com"),
+					expectFields(map[string]string{
+						"update_data_a":     "",
+						"update_data_cname": "example.com",
+					}),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,415 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &recordSetResource{}
+	_ resource.ResourceWithConfigure   = &recordSetResource{}
+	_ resource.ResourceWithModifyPlan  = &recordSetResource{}
+	_ resource.ResourceWithImportState = &recordSetResource{}
+)
+
+// NewTXTRecordSetResource is a helper function to simplify the provider implementation.
+func NewTXTRecordSetResource() resource.Resource {
+	return &recordSetResource{
+		recordType: ddnsnow.RecordTypeTXT,
+	}
+}
+
+// NewNSRecordSetResource is a helper function to simplify the provider implementation.
+func NewNSRecordSetResource() resource.Resource {
+	return &recordSetResource{
+		recordType: ddnsnow.RecordTypeNS,
+	}
+}
+
+// recordSetResource is the resource implementation shared by the
+// multi-valued record types.
+type recordSetResource struct {
+	client         ddnsnow.Client
+	recordType     ddnsnow.RecordType
+	plannedRecords *plannedRecords
+}
+
+// Metadata returns the resource type name.
+func (r *recordSetResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + strings.ToLower(string(r.recordType)) + "_record_set"
+}
+
+// Schema defines the schema for the resource.
+func (r *recordSetResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages a set of " + string(r.recordType) + " records in a single request. " +
+			string(r.recordType) + " records not in the set, e.g. those managed by other resources, are preserved. " +
+			"Adding a value which is already published fails, so that its owner does not lose it when the set is destroyed.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The identifier of the resource. Always `" + string(r.recordType) + "`.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"values": schema.SetAttribute{
+				Description: "The values of the " + string(r.recordType) + " records owned by this resource. At least one value is required.",
+				ElementType: types.StringType,
+				Required:    true,
+				Validators: []validator.Set{
+					// An empty set would own nothing, so it would be
+					// removed from the state on every refresh.
+					setvalidator.SizeAtLeast(1),
+					recordValuesValidator{recordType: r.recordType},
+				},
+			},
+		},
+	}
+}
+
+// ModifyPlan fails the plan when a value cannot coexist with a record
+// planned by another resource, and warns when it cannot coexist with a record
+// published on the domain.
+func (r *recordSetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var prior []string
+	if !req.State.Raw.IsNull() {
+		var state recordSetResourceModel
+		diags := req.State.Get(ctx, &state)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		resp.Diagnostics.Append(state.Values.ElementsAs(ctx, &prior, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		r.plannedRecords.own(r.records(prior))
+	}
+
+	// The resource is destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan recordSetResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || plan.Values.IsUnknown() {
+		return
+	}
+
+	var values []types.String
+	resp.Diagnostics.Append(plan.Values.ElementsAs(ctx, &values, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var known, added []string
+	for _, value := range values {
+		if value.IsUnknown() {
+			continue
+		}
+		known = append(known, value.ValueString())
+		// Values already owned have been checked when they were planned
+		if !slices.Contains(prior, value.ValueString()) {
+			added = append(added, value.ValueString())
+		}
+	}
+
+	resp.Diagnostics.Append(r.plannedRecords.keepAll(path.Root("values"), r.records(known))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(r.plannedRecords.checkPublished(ctx, path.Root("values"), r.records(added))...)
+}
+
+// Create creates the resource and sets the initial Terraform state.
+func (r *recordSetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan recordSetResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var values []string
+	resp.Diagnostics.Append(plan.Values.ElementsAs(ctx, &values, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Add every value of the set
+	err := r.client.UpdateRecords(ctx, nil, r.records(values))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating record set",
+			r.errorDetail("Could not create record set", err),
+		)
+		return
+	}
+
+	// Populate Computed attribute values
+	plan.ID = types.StringValue(string(r.recordType))
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *recordSetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state recordSetResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var owned []string
+	resp.Diagnostics.Append(state.Values.ElementsAs(ctx, &owned, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get refreshed record values from DDNS Now
+	settings, err := r.client.GetSettings(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading DDNS Now Record Set",
+			clientErrorDetail("Could not read DDNS Now records", err),
+		)
+		return
+	}
+
+	// Keep only the owned values which still exist, so that Terraform
+	// proposes to add the missing ones again
+	var values []string
+	for _, value := range owned {
+		if slices.Contains(settings.Records[r.recordType], value) {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Overwrite items with refreshed state
+	state.ID = types.StringValue(string(r.recordType))
+	state.Values, diags = types.SetValueFrom(ctx, types.StringType, values)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *recordSetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from state
+	var state recordSetResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Retrieve values from plan
+	var plan recordSetResourceModel
+	diags = req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var oldValues, newValues []string
+	resp.Diagnostics.Append(state.Values.ElementsAs(ctx, &oldValues, false)...)
+	resp.Diagnostics.Append(plan.Values.ElementsAs(ctx, &newValues, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Submit only the difference between the sets
+	var removed, added []string
+	for _, value := range oldValues {
+		if !slices.Contains(newValues, value) {
+			removed = append(removed, value)
+		}
+	}
+	for _, value := range newValues {
+		if !slices.Contains(oldValues, value) {
+			added = append(added, value)
+		}
+	}
+
+	err := r.client.UpdateRecords(ctx, r.records(removed), r.records(added))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating DDNS Now Record Set",
+			r.errorDetail("Could not update record set", err),
+		)
+		return
+	}
+
+	// Update resource state with updated record set
+	plan.ID = types.StringValue(string(r.recordType))
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+func (r *recordSetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Retrieve values from state
+	var state recordSetResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var values []string
+	resp.Diagnostics.Append(state.Values.ElementsAs(ctx, &values, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Remove every owned value, leaving the others untouched
+	err := r.client.UpdateRecords(ctx, r.records(values), nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting DDNS Now Record Set",
+			clientErrorDetail("Could not delete record set", err),
+		)
+		return
+	}
+}
+
+// ImportState takes over every value of the record type published on the
+// domain. The import ID is the record type.
+func (r *recordSetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if !strings.EqualFold(req.ID, string(r.recordType)) {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			fmt.Sprintf("The import ID must be %q, got: %q", r.recordType, req.ID),
+		)
+		return
+	}
+
+	settings, err := r.client.GetSettings(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Importing DDNS Now Record Set",
+			clientErrorDetail("Could not read DDNS Now records", err),
+		)
+		return
+	}
+	values := settings.Records[r.recordType]
+	if len(values) == 0 {
+		resp.Diagnostics.AddError(
+			"Error Importing DDNS Now Record Set",
+			fmt.Sprintf("The domain has no %s records to import.", r.recordType),
+		)
+		return
+	}
+
+	state := recordSetResourceModel{
+		ID: types.StringValue(string(r.recordType)),
+	}
+	var diags diag.Diagnostics
+	state.Values, diags = types.SetValueFrom(ctx, types.StringType, values)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// errorDetail builds the detail of a diagnostic for an error returned by
+// the DDNS Now client, explaining how to take over values which are already
+// published.
+func (r *recordSetResource) errorDetail(message string, err error) string {
+	if !errors.Is(err, ddnsnow.ErrAlreadyExists) {
+		return clientErrorDetail(message, err)
+	}
+
+	return message + ": " + err.Error() + "\n\n" +
+		"The value is already published on the domain, e.g. by another resource, which would lose it once this resource is destroyed. " +
+		"Remove the value from the set, or import the record set with the ID `" + string(r.recordType) + "`, " +
+		"which takes over every " + string(r.recordType) + " value of the domain."
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *recordSetResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(ddnsnow.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected ddnsnow.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	if data, ok := req.ProviderData.(*configuredClient); ok {
+		r.plannedRecords = data.plannedRecords
+	}
+}
+
+// records converts values into records of the type of the resource.
+func (r *recordSetResource) records(values []string) []ddnsnow.Record {
+	records := make([]ddnsnow.Record, 0, len(values))
+	for _, value := range values {
+		records = append(records, ddnsnow.Record{
+			Type:  r.recordType,
+			Value: value,
+		})
+	}
+	return records
+}
+
+// recordSetResourceModel maps the resource schema data.
+type recordSetResourceModel struct {
+	ID     types.String `tfsdk:"id"`
+	Values types.Set    `tfsdk:"values"`
+}
@@ -0,0 +1,194 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"terraform-provider-ddnsnow/pkg/ddnsnow/ddnsnowtest"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccTXTRecordSetResource(t *testing.T) {
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	// A value owned by someone else, which must be preserved
+	testServer.SetField("update_data_txt", "other")
+
+	// The order of the values in a set is unspecified
+	expectTXT := func(want ...string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			got := strings.Split(testServer.Field("update_data_txt"), "\n")
+			slices.Sort(got)
+			if !slices.Equal(got, want) {
+				return fmt.Errorf("unexpected TXT records: %q", got)
+			}
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_txt_record_set" "test" {
+  values = ["record1", "record2"]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ddnsnow_txt_record_set.test", "id", "TXT"),
+					resource.TestCheckResourceAttr("ddnsnow_txt_record_set.test", "values.#", "2"),
+					resource.TestCheckTypeSetElemAttr("ddnsnow_txt_record_set.test", "values.*", "record1"),
+					resource.TestCheckTypeSetElemAttr("ddnsnow_txt_record_set.test", "values.*", "record2"),
+					expectTXT("other", "record1", "record2"),
+				),
+			},
+			// Owned values removed out of band are added again
+			{
+				PreConfig: func() {
+					testServer.SetField("update_data_txt", "other\nrecord2")
+				},
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_txt_record_set" "test" {
+  values = ["record1", "record2"]
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("ddnsnow_txt_record_set.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: expectTXT("other", "record1", "record2"),
+			},
+			// Update and Read testing
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_txt_record_set" "test" {
+  values = ["record2", "record3"]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ddnsnow_txt_record_set.test", "values.#", "2"),
+					resource.TestCheckTypeSetElemAttr("ddnsnow_txt_record_set.test", "values.*", "record3"),
+					expectTXT("other", "record2", "record3"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+		CheckDestroy: expectTXT("other"),
+	})
+}
+
+func TestAccTXTRecordSetResourceRejectsEmptyValues(t *testing.T) {
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_txt_record_set" "test" {
+  values = []
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Value`),
+			},
+		},
+	})
+}
+
+func TestAccTXTRecordSetResourceRejectsPublishedValues(t *testing.T) {
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	testServer.SetField("update_data_txt", "other")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The value owned by someone else is not taken over
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_txt_record_set" "test" {
+  values = ["other", "record"]
+}
+`,
+				ExpectError: regexp.MustCompile(`Error creating record set`),
+			},
+			// Importing takes over every value explicitly
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_txt_record_set" "test" {
+  values = ["other"]
+}
+`,
+				ResourceName:       "ddnsnow_txt_record_set.test",
+				ImportState:        true,
+				ImportStateId:      "TXT",
+				ImportStatePersist: true,
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 || states[0].Attributes["values.#"] != "1" {
+						return fmt.Errorf("unexpected import result: %v", states)
+					}
+					return nil
+				},
+			},
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_txt_record_set" "test" {
+  values = ["other"]
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+		},
+		CheckDestroy: func(*terraform.State) error {
+			if got := testServer.Field("update_data_txt"); got != "" {
+				return fmt.Errorf("unexpected TXT records: %q", got)
+			}
+			return nil
+		},
+	})
+}
+
+func TestAccNSRecordSetResource(t *testing.T) {
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_ns_record_set" "test" {
+  values = ["ns1.example.com", "ns2.example.com"]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ddnsnow_ns_record_set.test", "id", "NS"),
+					resource.TestCheckResourceAttr("ddnsnow_ns_record_set.test", "values.#", "2"),
+					func(*terraform.State) error {
+						if got := testServer.Field("update_data_ns"); got != "ns1.example.com\nns2.example.com" && got != "ns2.example.com\nns1.example.com" {
+							return fmt.Errorf("unexpected NS records: %q", got)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
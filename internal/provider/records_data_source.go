@@ -0,0 +1,225 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &recordsDataSource{}
+	_ datasource.DataSourceWithConfigure = &recordsDataSource{}
+)
+
+// NewRecordsDataSource is a helper function to simplify the provider implementation.
+func NewRecordsDataSource() datasource.DataSource {
+	return &recordsDataSource{}
+}
+
+// recordsDataSource is the data source implementation.
+type recordsDataSource struct {
+	client ddnsnow.Client
+}
+
+// Metadata returns the data source type name.
+func (d *recordsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_records"
+}
+
+// Schema defines the schema for the data source.
+func (d *recordsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists every record currently published on the domain.",
+		Attributes: map[string]schema.Attribute{
+			"type": schema.StringAttribute{
+				Description: "Only expose records of this type. One of: `A`, `AAAA`, `CNAME`, `TXT`, `NS`.",
+				Optional:    true,
+				Validators: []validator.String{
+					recordTypeValidator{},
+				},
+			},
+			"a": schema.StringAttribute{
+				Description: "The value of the A record, if any.",
+				Computed:    true,
+			},
+			"aaaa": schema.StringAttribute{
+				Description: "The value of the AAAA record, if any.",
+				Computed:    true,
+			},
+			"cname": schema.StringAttribute{
+				Description: "The value of the CNAME record, if any.",
+				Computed:    true,
+			},
+			"ns": schema.ListAttribute{
+				Description: "The values of the NS records.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+			"txt": schema.ListAttribute{
+				Description: "The values of the TXT records.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+			"wildcard": schema.BoolAttribute{
+				Description: "Whether wildcard resolution is enabled for the domain.",
+				Computed:    true,
+			},
+			"zone_file": schema.StringAttribute{
+				Description: "The records as a BIND-style zone file fragment relative to the domain, e.g. for backups. " +
+					"Wildcard resolution is written as the records repeated for the `*` owner.",
+				Computed: true,
+			},
+			"records": schema.ListNestedAttribute{
+				Description: "Every record of the domain as a type and value pair.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							Description: "The record type.",
+							Computed:    true,
+						},
+						"value": schema.StringAttribute{
+							Description: "The record value.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *recordsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state recordsDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filter := ddnsnow.RecordType(state.Type.ValueString())
+
+	settings, err := d.client.GetSettings(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read DDNS Now Records",
+			clientErrorDetail("Could not read DDNS Now settings", err),
+		)
+		return
+	}
+
+	// Expose only the records passing the filter, keeping the others null
+	included := func(typ ddnsnow.RecordType) bool {
+		return filter == "" || filter == typ
+	}
+
+	single := func(typ ddnsnow.RecordType) types.String {
+		if !included(typ) || len(settings.Records[typ]) == 0 {
+			return types.StringNull()
+		}
+		return types.StringValue(settings.Records[typ][0])
+	}
+	state.A = single(ddnsnow.RecordTypeA)
+	state.AAAA = single(ddnsnow.RecordTypeAAAA)
+	state.CNAME = single(ddnsnow.RecordTypeCNAME)
+
+	multiple := func(typ ddnsnow.RecordType) types.List {
+		if !included(typ) {
+			return types.ListNull(types.StringType)
+		}
+		list, diags := types.ListValueFrom(ctx, types.StringType, append([]string{}, settings.Records[typ]...))
+		resp.Diagnostics.Append(diags...)
+		return list
+	}
+	state.NS = multiple(ddnsnow.RecordTypeNS)
+	state.TXT = multiple(ddnsnow.RecordTypeTXT)
+
+	state.Wildcard = types.BoolValue(settings.EnableWildcard)
+
+	state.Records = []recordsDataSourceRecordModel{}
+	zone := &ddnsnow.Settings{
+		Records:        map[ddnsnow.RecordType][]string{},
+		EnableWildcard: settings.EnableWildcard,
+	}
+	for _, typ := range ddnsnow.RecordTypes {
+		if !included(typ) {
+			continue
+		}
+		zone.Records[typ] = settings.Records[typ]
+		for _, value := range settings.Records[typ] {
+			state.Records = append(state.Records, recordsDataSourceRecordModel{
+				Type:  types.StringValue(string(typ)),
+				Value: types.StringValue(value),
+			})
+		}
+	}
+
+	var zoneFile strings.Builder
+	if err := ddnsnow.WriteZone(&zoneFile, zone); err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Render DDNS Now Zone File",
+			err.Error(),
+		)
+		return
+	}
+	state.ZoneFile = types.StringValue(zoneFile.String())
+
+	// Set state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *recordsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(ddnsnow.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected ddnsnow.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+// recordsDataSourceModel maps the data source schema data.
+type recordsDataSourceModel struct {
+	Type     types.String                   `tfsdk:"type"`
+	A        types.String                   `tfsdk:"a"`
+	AAAA     types.String                   `tfsdk:"aaaa"`
+	CNAME    types.String                   `tfsdk:"cname"`
+	NS       types.List                     `tfsdk:"ns"`
+	TXT      types.List                     `tfsdk:"txt"`
+	Wildcard types.Bool                     `tfsdk:"wildcard"`
+	ZoneFile types.String                   `tfsdk:"zone_file"`
+	Records  []recordsDataSourceRecordModel `tfsdk:"records"`
+}
+
+// recordsDataSourceRecordModel maps the nested records schema data.
+type recordsDataSourceRecordModel struct {
+	Type  types.String `tfsdk:"type"`
+	Value types.String `tfsdk:"value"`
+}
@@ -0,0 +1,61 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+	"terraform-provider-ddnsnow/pkg/ddnsnow/ddnsnowtest"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccRecordsDataSource(t *testing.T) {
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "127.0.0.1")
+	testServer.SetRecords(ddnsnow.RecordTypeTXT, "record1", "record2")
+	testServer.SetWildcard(true)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+data "ddnsnow_records" "all" {}
+
+data "ddnsnow_records" "txt" {
+  type = "TXT"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.ddnsnow_records.all", "a", "127.0.0.1"),
+					resource.TestCheckNoResourceAttr("data.ddnsnow_records.all", "aaaa"),
+					resource.TestCheckNoResourceAttr("data.ddnsnow_records.all", "cname"),
+					resource.TestCheckResourceAttr("data.ddnsnow_records.all", "ns.#", "0"),
+					resource.TestCheckResourceAttr("data.ddnsnow_records.all", "txt.#", "2"),
+					resource.TestCheckResourceAttr("data.ddnsnow_records.all", "txt.0", "record1"),
+					resource.TestCheckResourceAttr("data.ddnsnow_records.all", "txt.1", "record2"),
+					resource.TestCheckResourceAttr("data.ddnsnow_records.all", "wildcard", "true"),
+					resource.TestCheckResourceAttr("data.ddnsnow_records.all", "records.#", "3"),
+					resource.TestCheckResourceAttr("data.ddnsnow_records.all", "records.0.type", "A"),
+					resource.TestCheckResourceAttr("data.ddnsnow_records.all", "records.0.value", "127.0.0.1"),
+					resource.TestCheckResourceAttr("data.ddnsnow_records.all", "zone_file",
+						"@\tIN\tA\t127.0.0.1\n@\tIN\tTXT\t\"record1\"\n@\tIN\tTXT\t\"record2\"\n"+
+							"*\tIN\tA\t127.0.0.1\n*\tIN\tTXT\t\"record1\"\n*\tIN\tTXT\t\"record2\"\n"),
+
+					resource.TestCheckNoResourceAttr("data.ddnsnow_records.txt", "a"),
+					resource.TestCheckResourceAttr("data.ddnsnow_records.txt", "txt.#", "2"),
+					resource.TestCheckResourceAttr("data.ddnsnow_records.txt", "records.#", "2"),
+					resource.TestCheckResourceAttr("data.ddnsnow_records.txt", "records.1.type", "TXT"),
+					resource.TestCheckResourceAttr("data.ddnsnow_records.txt", "records.1.value", "record2"),
+					resource.TestCheckResourceAttr("data.ddnsnow_records.txt", "zone_file",
+						"@\tIN\tTXT\t\"record1\"\n@\tIN\tTXT\t\"record2\"\n*\tIN\tTXT\t\"record1\"\n*\tIN\tTXT\t\"record2\"\n"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,126 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// recordTypesDescription lists the supported record types for diagnostics.
+func recordTypesDescription() string {
+	names := make([]string, 0, len(ddnsnow.RecordTypes))
+	for _, typ := range ddnsnow.RecordTypes {
+		names = append(names, string(typ))
+	}
+	return strings.Join(names, ", ")
+}
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ validator.String = recordTypeValidator{}
+	_ validator.String = recordValueValidator{}
+	_ validator.Set    = recordValuesValidator{}
+)
+
+// recordTypeValidator validates that a string is a supported record type.
+type recordTypeValidator struct{}
+
+func (v recordTypeValidator) Description(_ context.Context) string {
+	return "value must be one of: " + recordTypesDescription()
+}
+
+func (v recordTypeValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v recordTypeValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if !slices.Contains(ddnsnow.RecordTypes, ddnsnow.RecordType(req.ConfigValue.ValueString())) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Record Type",
+			"The record type must be one of: "+recordTypesDescription()+". Got: "+req.ConfigValue.ValueString(),
+		)
+	}
+}
+
+// recordValueValidator validates that a string is a valid value for records
+// of a fixed type.
+type recordValueValidator struct {
+	recordType ddnsnow.RecordType
+}
+
+func (v recordValueValidator) Description(_ context.Context) string {
+	return "value must be a valid " + string(v.recordType) + " record value"
+}
+
+func (v recordValueValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v recordValueValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	resp.Diagnostics.Append(validateRecordValue(req.Path, v.recordType, req.ConfigValue)...)
+}
+
+// recordValuesValidator validates that every element of a set is a valid
+// value for records of a fixed type.
+type recordValuesValidator struct {
+	recordType ddnsnow.RecordType
+}
+
+func (v recordValuesValidator) Description(_ context.Context) string {
+	return "values must be valid " + string(v.recordType) + " record values"
+}
+
+func (v recordValuesValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v recordValuesValidator) ValidateSet(_ context.Context, req validator.SetRequest, resp *validator.SetResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	for _, element := range req.ConfigValue.Elements() {
+		value, ok := element.(types.String)
+		if !ok {
+			continue
+		}
+		resp.Diagnostics.Append(validateRecordValue(req.Path.AtSetValue(value), v.recordType, value)...)
+	}
+}
+
+// validateRecordValue returns an attribute error at p if value is not a valid
+// value for records of recordType. Unknown and null values are skipped.
+func validateRecordValue(p path.Path, recordType ddnsnow.RecordType, value types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if value.IsNull() || value.IsUnknown() {
+		return diags
+	}
+
+	record := ddnsnow.Record{
+		Type:  recordType,
+		Value: value.ValueString(),
+	}
+	if err := record.Validate(); err != nil {
+		diags.AddAttributeError(
+			p,
+			"Invalid "+string(recordType)+" Record Value",
+			strings.TrimPrefix(err.Error(), ddnsnow.ErrInvalidRecord.Error()+": "),
+		)
+	}
+
+	return diags
+}
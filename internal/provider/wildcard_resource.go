@@ -0,0 +1,215 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// wildcardID is the identifier of the wildcard resource, which exists once
+// per domain.
+const wildcardID = "wildcard"
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &wildcardResource{}
+	_ resource.ResourceWithConfigure   = &wildcardResource{}
+	_ resource.ResourceWithImportState = &wildcardResource{}
+)
+
+// NewWildcardResource is a helper function to simplify the provider implementation.
+func NewWildcardResource() resource.Resource {
+	return &wildcardResource{}
+}
+
+// wildcardResource is the resource implementation.
+type wildcardResource struct {
+	client ddnsnow.Client
+}
+
+// Metadata returns the resource type name.
+func (r *wildcardResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_wildcard"
+}
+
+// Schema defines the schema for the resource.
+func (r *wildcardResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages wildcard resolution of the domain. Declare at most one per domain. " +
+			"Destroying the resource disables wildcard resolution.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The identifier of the resource. Always `" + wildcardID + "`.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"enabled": schema.BoolAttribute{
+				Description: "Whether subdomains of the domain resolve to the records of the domain.",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// Create creates the resource and sets the initial Terraform state.
+func (r *wildcardResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan wildcardResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the wildcard setting
+	err := r.client.SetWildcard(ctx, plan.Enabled.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Setting DDNS Now Wildcard",
+			clientErrorDetail("Could not set wildcard", err),
+		)
+		return
+	}
+
+	// Populate Computed attribute values
+	plan.ID = types.StringValue(wildcardID)
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *wildcardResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state wildcardResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get refreshed wildcard setting from DDNS Now
+	settings, err := r.client.GetSettings(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading DDNS Now Wildcard",
+			clientErrorDetail("Could not read wildcard", err),
+		)
+		return
+	}
+
+	// Overwrite items with refreshed state
+	state.ID = types.StringValue(wildcardID)
+	state.Enabled = types.BoolValue(settings.EnableWildcard)
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *wildcardResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan
+	var plan wildcardResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Apply the wildcard setting
+	err := r.client.SetWildcard(ctx, plan.Enabled.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Setting DDNS Now Wildcard",
+			clientErrorDetail("Could not set wildcard", err),
+		)
+		return
+	}
+
+	// Update resource state with updated setting
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete disables wildcard resolution and removes the Terraform state on
+// success.
+func (r *wildcardResource) Delete(ctx context.Context, _ resource.DeleteRequest, resp *resource.DeleteResponse) {
+	err := r.client.SetWildcard(ctx, false)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Disabling DDNS Now Wildcard",
+			clientErrorDetail("Could not disable wildcard", err),
+		)
+		return
+	}
+}
+
+// ImportState adopts the wildcard setting of the domain. The import ID is
+// ignored, as there is only one wildcard setting per domain.
+func (r *wildcardResource) ImportState(ctx context.Context, _ resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	settings, err := r.client.GetSettings(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Importing DDNS Now Wildcard",
+			clientErrorDetail("Could not read wildcard", err),
+		)
+		return
+	}
+
+	state := wildcardResourceModel{
+		ID:      types.StringValue(wildcardID),
+		Enabled: types.BoolValue(settings.EnableWildcard),
+	}
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *wildcardResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(ddnsnow.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected ddnsnow.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// wildcardResourceModel maps the resource schema data.
+type wildcardResourceModel struct {
+	ID      types.String `tfsdk:"id"`
+	Enabled types.Bool   `tfsdk:"enabled"`
+}
@@ -0,0 +1,85 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"terraform-provider-ddnsnow/pkg/ddnsnow/ddnsnowtest"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccWildcardResource(t *testing.T) {
+	testServer := ddnsnowtest.NewServer()
+	defer testServer.Close()
+
+	wildcardEnabled := func(want bool) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			if got := testServer.Field("update_data_wildcard") != ""; got != want {
+				return fmt.Errorf("unexpected wildcard setting: %t", got)
+			}
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_wildcard" "test" {
+  enabled = true
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ddnsnow_wildcard.test", "id", "wildcard"),
+					resource.TestCheckResourceAttr("ddnsnow_wildcard.test", "enabled", "true"),
+					wildcardEnabled(true),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "ddnsnow_wildcard.test",
+				ImportState:       true,
+				ImportStateId:     "wildcard",
+				ImportStateVerify: true,
+			},
+			// Drift detection testing
+			{
+				PreConfig: func() {
+					testServer.SetField("update_data_wildcard", "")
+				},
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_wildcard" "test" {
+  enabled = true
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("ddnsnow_wildcard.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: wildcardEnabled(true),
+			},
+			// Update and Read testing
+			{
+				Config: fmt.Sprintf(providerConfigTpl, testServer.URL) + `
+resource "ddnsnow_wildcard" "test" {
+  enabled = false
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ddnsnow_wildcard.test", "enabled", "false"),
+					wildcardEnabled(false),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
@@ -24,21 +24,44 @@ var (
 	DDNSNowResultNG ddnsNowResult = "NG"
 )
 
+// checkStatus fails for HTTP error statuses, whose body is never a
+// meaningful DDNS Now response.
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= http.StatusBadRequest {
+		return &HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
 func handleResponse(resp *http.Response) error {
+	_, err := decodeResponse(resp)
+	return err
+}
+
+// decodeResponse reads the JSON response of DDNS Now, failing unless the
+// request succeeded.
+func decodeResponse(resp *http.Response) (*ddnsNowResponse, error) {
 	defer resp.Body.Close()
 
+	if err := checkStatus(resp); err != nil {
+		return nil, err
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("read body: %w", err)
+		return nil, fmt.Errorf("read body: %w", err)
 	}
 
 	var ddnsNowResp ddnsNowResponse
 	if err := json.Unmarshal(body, &ddnsNowResp); err != nil {
-		return fmt.Errorf("unmarshal body: %w", err)
+		if isLoginPage(body) {
+			return nil, fmt.Errorf("%w: DDNS Now responded with the login page", ErrUnauthorized)
+		}
+		return nil, fmt.Errorf("unmarshal body: %w", err)
 	}
 	if ddnsNowResult(ddnsNowResp.Result) != DDNSNowResultOK {
-		return fmt.Errorf("ddnsnow: code=%d, msg=%s", ddnsNowResp.ErrorCode, ddnsNowResp.ErrorMsg)
+		return nil, &APIError{Code: ddnsNowResp.ErrorCode, Msg: ddnsNowResp.ErrorMsg}
 	}
 
-	return nil
+	return &ddnsNowResp, nil
 }
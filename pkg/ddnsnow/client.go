@@ -4,17 +4,27 @@
 package ddnsnow
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 )
 
 type Client interface {
-	GetRecord(record Record) (Record, error)
-	CreateRecord(record Record) error
-	UpdateRecord(oldRecord, newRecord Record) error
-	DeleteRecord(record Record) error
+	GetSettings(ctx context.Context) (*Settings, error)
+	GetRecord(ctx context.Context, record Record) (Record, error)
+	CreateRecord(ctx context.Context, record Record) error
+	UpdateRecord(ctx context.Context, oldRecord, newRecord Record) error
+	DeleteRecord(ctx context.Context, record Record) error
+	SetWildcard(ctx context.Context, enabled bool) error
+	SetSettings(ctx context.Context, settings *Settings) error
+	UpdateRecords(ctx context.Context, remove, add []Record) error
 }
 
 var _ Client = &client{}
@@ -22,123 +32,293 @@ var _ Client = &client{}
 type client struct {
 	httpClient *http.Client
 	uiURL      url.URL
-	uiCookie   string
+	username   string
+	lock       domainLock
+
+	password string
+	loginMu  sync.Mutex
+
+	conflictRetries int
+	retryPolicy     RetryPolicy
+	limiter         *rateLimiter
+}
+
+// ClientOption configures optional behaviour of the client.
+type ClientOption func(*client)
+
+// WithConflictRetries sets how many times a mutation is applied again on top
+// of the latest settings when they were changed by someone else in the
+// meantime. Zero makes the client fail with a ConflictError immediately.
+func WithConflictRetries(n int) ClientOption {
+	return func(c *client) {
+		c.conflictRetries = n
+	}
 }
 
-func NewClient(username, passwordHash, server *string) (*client, error) {
-	var err error
-	var uiURL *url.URL
-	if *server != "" {
-		uiURL, err = url.Parse(*server)
+// serverURL returns the URL of path on server, which defaults to DDNS Now.
+func serverURL(server, path string) (*url.URL, error) {
+	u := &url.URL{
+		Scheme: "https",
+		Host:   "f5.si",
+	}
+	if server != "" {
+		var err error
+		u, err = url.Parse(server)
 		if err != nil {
 			return nil, fmt.Errorf("server URL parsing: %w", err)
 		}
-	} else {
-		uiURL = &url.URL{
-			Scheme: "https",
-			Host:   "f5.si",
-		}
 	}
-	uiURL.Path = "/control.php"
-
-	uiCookie := fmt.Sprintf("cookie_loginuser=domain%%3D%s%%3Bpassword_hash%%3D%s%%3B", *username, *passwordHash)
-
-	return &client{
-		httpClient: &http.Client{},
-		uiURL:      *uiURL,
-		uiCookie:   uiCookie,
-	}, nil
+	u.Path = path
+	return u, nil
 }
 
-func (c *client) queryUI(body url.Values) error {
-	ukey := "UKEY@061e10718b1455b638af4a55a8377a01"
-
-	body.Add("action", "update")
-	body.Add("json", "1")
-	body.Add("ukey", ukey)
-
-	req, err := http.NewRequest("POST", c.uiURL.String(), strings.NewReader(body.Encode()))
+func NewClient(username, passwordHash, server *string, opts ...ClientOption) (*client, error) {
+	uiURL, err := serverURL(*server, "/control.php")
 	if err != nil {
-		return fmt.Errorf("http request construction: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Cookie", c.uiCookie)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	resp, err := c.httpClient.Do(req)
+	jar, err := cookiejar.New(nil)
 	if err != nil {
-		return fmt.Errorf("http request: %w", err)
+		return nil, fmt.Errorf("cookie jar construction: %w", err)
 	}
 
-	return handleResponse(resp)
+	c := &client{
+		httpClient:      &http.Client{Jar: jar},
+		uiURL:           *uiURL,
+		username:        *username,
+		lock:            lockFor(uiURL.Host + "/" + *username),
+		conflictRetries: 2,
+		retryPolicy:     DefaultRetryPolicy(),
+		limiter:         newRateLimiter(0, 1),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// Without a password, the session is derived from the password hash
+	// and never expires.
+	if c.password == "" {
+		c.setSession(fmt.Sprintf("domain%%3D%s%%3Bpassword_hash%%3D%s%%3B", *username, *passwordHash))
+	}
+
+	return c, nil
 }
 
-func (c *client) GetSettings() (*settings, error) {
-	req, err := http.NewRequest("GET", c.uiURL.String(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("http request construction: %w", err)
+// do sends an authenticated request to the control page once the rate limit
+// allows it.
+func (c *client) do(req *http.Request) (*http.Response, error) {
+	if err := c.limiter.wait(req.Context()); err != nil {
+		return nil, err
 	}
 
-	req.Header.Set("Cookie", c.uiCookie)
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("http request: %w", err)
+		return nil, fmt.Errorf("http request: %w", redactQuery(err))
 	}
-	defer resp.Body.Close()
 
-	return parseSettings(resp.Body)
-}
-
-func (c *client) GetRecord(record Record) (Record, error) {
-	settings, err := c.GetSettings()
-	if err != nil {
-		return Record{}, err
+	if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		c.limiter.pause(retryAfter)
 	}
 
-	return settings.getRecord(record)
+	return resp, nil
 }
 
-func (c *client) CreateRecord(record Record) error {
-	settings, err := c.GetSettings()
-	if err != nil {
+// redactQuery removes the query from the URL reported by err, as it holds the
+// token of the update API, so that the token does not end up in logs.
+func redactQuery(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
 		return err
 	}
+	urlErr.URL, _, _ = strings.Cut(urlErr.URL, "?")
+	return err
+}
 
-	if err := settings.addRecord(record); err != nil {
-		return err
+// defaultUKey is submitted when the control page does not provide a ukey.
+const defaultUKey = "UKEY@061e10718b1455b638af4a55a8377a01"
+
+func (c *client) queryUI(ctx context.Context, body url.Values) error {
+	// Fields of the control page may include an action of their own, which
+	// must never replace the update.
+	body.Set("action", "update")
+	body.Set("json", "1")
+	if body.Get("ukey") == "" {
+		body.Set("ukey", defaultUKey)
 	}
+	encodedBody := body.Encode()
 
-	return c.queryUI(settings.values())
+	// The body is a full snapshot of the settings, so submitting it more
+	// than once is harmless.
+	return c.withSession(ctx, func() error {
+		return c.retry(ctx, true, func() error {
+			req, err := http.NewRequestWithContext(ctx, "POST", c.uiURL.String(), strings.NewReader(encodedBody))
+			if err != nil {
+				return fmt.Errorf("http request construction: %w", err)
+			}
+
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			resp, err := c.do(req)
+			if err != nil {
+				return err
+			}
+
+			return handleResponse(resp)
+		})
+	})
 }
 
-func (c *client) UpdateRecord(oldRecord, newRecord Record) error {
-	if oldRecord.Type != newRecord.Type {
-		return fmt.Errorf("type mismatch: old=%s, new=%s", oldRecord.Type, newRecord.Type)
-	}
+func (c *client) GetSettings(ctx context.Context) (*Settings, error) {
+	var settings *Settings
+	err := c.withSession(ctx, func() error {
+		return c.retry(ctx, true, func() error {
+			req, err := http.NewRequestWithContext(ctx, "GET", c.uiURL.String(), nil)
+			if err != nil {
+				return fmt.Errorf("http request construction: %w", err)
+			}
 
-	settings, err := c.GetSettings()
+			resp, err := c.do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if err := checkStatus(resp); err != nil {
+				return err
+			}
+
+			settings, err = parseSettings(resp.Body)
+			return err
+		})
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := settings.removeRecord(oldRecord); err != nil {
+	return settings, nil
+}
+
+// mutateSettings fetches the current settings, applies mutate to them and
+// submits the result. The whole cycle holds the domain lock, because the
+// submitted settings replace everything configured for the domain.
+//
+// The domain lock does not cover changes made outside of this process, e.g.
+// in the web UI, so the settings are fetched again right before submitting.
+// If they have changed, mutate is applied again on top of the latest
+// settings, at most conflictRetries times.
+func (c *client) mutateSettings(ctx context.Context, mutate func(*Settings) error) error {
+	if err := c.lock.lock(ctx); err != nil {
 		return err
 	}
-	if err := settings.addRecord(newRecord); err != nil {
+	defer c.lock.unlock()
+
+	current, err := c.GetSettings(ctx)
+	if err != nil {
 		return err
 	}
 
-	return c.queryUI(settings.values())
+	for attempt := 0; ; attempt++ {
+		fingerprint := current.fingerprint()
+		if err := mutate(current); err != nil {
+			return err
+		}
+
+		latest, err := c.GetSettings(ctx)
+		if err != nil {
+			return err
+		}
+		if latest.fingerprint() == fingerprint {
+			return c.queryUI(ctx, current.submission(latest))
+		}
+
+		if attempt >= c.conflictRetries {
+			return &ConflictError{Attempts: attempt + 1}
+		}
+		current = latest
+	}
 }
 
-func (c *client) DeleteRecord(record Record) error {
-	settings, err := c.GetSettings()
+func (c *client) GetRecord(ctx context.Context, record Record) (Record, error) {
+	settings, err := c.GetSettings(ctx)
 	if err != nil {
-		return err
+		return Record{}, err
+	}
+
+	return settings.getRecord(record)
+}
+
+func (c *client) CreateRecord(ctx context.Context, record Record) error {
+	return c.mutateSettings(ctx, func(settings *Settings) error {
+		return settings.addRecord(record)
+	})
+}
+
+func (c *client) UpdateRecord(ctx context.Context, oldRecord, newRecord Record) error {
+	if oldRecord.Type != newRecord.Type {
+		return fmt.Errorf("type mismatch: old=%s, new=%s", oldRecord.Type, newRecord.Type)
 	}
 
-	if err := settings.removeRecord(record); err != nil {
+	return c.mutateSettings(ctx, func(settings *Settings) error {
+		if err := settings.removeRecord(oldRecord); err != nil {
+			return err
+		}
+		return settings.addRecord(newRecord)
+	})
+}
+
+func (c *client) DeleteRecord(ctx context.Context, record Record) error {
+	return c.mutateSettings(ctx, func(settings *Settings) error {
+		return settings.removeRecord(record)
+	})
+}
+
+func (c *client) SetWildcard(ctx context.Context, enabled bool) error {
+	return c.mutateSettings(ctx, func(settings *Settings) error {
+		settings.EnableWildcard = enabled
+		return nil
+	})
+}
+
+// SetSettings replaces every record and the wildcard setting of the domain
+// with those of desired in a single submission.
+func (c *client) SetSettings(ctx context.Context, desired *Settings) error {
+	if err := desired.validate(); err != nil {
 		return err
 	}
 
-	return c.queryUI(settings.values())
+	return c.mutateSettings(ctx, func(settings *Settings) error {
+		settings.Records = map[RecordType][]string{}
+		for typ, records := range desired.Records {
+			settings.Records[typ] = slices.Clone(records)
+		}
+		settings.EnableWildcard = desired.EnableWildcard
+		return nil
+	})
+}
+
+// UpdateRecords removes and adds records in a single submission. Removing
+// records which do not exist is a no-op. Adding NS and TXT values which
+// already exist fails with ErrAlreadyExists, as they may be owned by someone
+// else, who would lose them once the caller removes them.
+func (c *client) UpdateRecords(ctx context.Context, remove, add []Record) error {
+	return c.mutateSettings(ctx, func(settings *Settings) error {
+		for _, record := range remove {
+			if err := settings.removeRecord(record); err != nil && !errors.Is(err, ErrNotFound) {
+				return err
+			}
+		}
+
+		for _, record := range add {
+			if record.Type == RecordTypeNS || record.Type == RecordTypeTXT {
+				if _, err := settings.getRecord(record); err == nil {
+					return fmt.Errorf("%w: %s", ErrAlreadyExists, record)
+				}
+			}
+			if err := settings.addRecord(record); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
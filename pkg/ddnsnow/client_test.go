@@ -4,12 +4,19 @@
 package ddnsnow_test
 
 import (
-	"io"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"slices"
+	"strings"
+	"sync"
 	"terraform-provider-ddnsnow/pkg/ddnsnow"
+	"terraform-provider-ddnsnow/pkg/ddnsnow/ddnsnowtest"
 	"testing"
+	"time"
 )
 
 var (
@@ -17,30 +24,35 @@ var (
 	passwordHash = "0123456789abcdef"
 )
 
-func TestClientGetSettings(t *testing.T) {
-	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			_, err := w.Write([]byte(`<html>
-<input type="text" id="update_data_a" value="127.0.0.1">
-<textarea id="update_data_txt">record1
-record2</textarea>
-<input type="checkbox" id="update_data_wildcard" checked>
-</html>`))
-			if err != nil {
-				t.Fatalf("Write: %v", err)
-			}
-		}
-	}))
-	defer testServer.Close()
-	server := testServer.URL
+// newTestServer starts an emulated control page accepting the test
+// credentials.
+func newTestServer(opts ...ddnsnowtest.Option) *ddnsnowtest.Server {
+	return ddnsnowtest.NewServer(append([]ddnsnowtest.Option{ddnsnowtest.WithCredentials(domain, passwordHash)}, opts...)...)
+}
 
-	client, err := ddnsnow.NewClient(&domain, &passwordHash, &server)
+// newTestClient returns a client of testServer logged in with the test
+// credentials.
+func newTestClient(t *testing.T, testServer *ddnsnowtest.Server, opts ...ddnsnow.ClientOption) ddnsnow.Client {
+	t.Helper()
+
+	server := testServer.URL
+	client, err := ddnsnow.NewClient(&domain, &passwordHash, &server, opts...)
 	if err != nil {
 		t.Fatalf("NewClient: %v", err)
 	}
+	return client
+}
+
+func TestClientGetSettings(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "127.0.0.1")
+	testServer.SetRecords(ddnsnow.RecordTypeTXT, "record1", "record2")
+	testServer.SetWildcard(true)
 
-	settings, err := client.GetSettings()
+	client := newTestClient(t, testServer)
+
+	settings, err := client.GetSettings(context.Background())
 	if err != nil {
 		t.Fatalf("GetSettings: %v", err)
 	}
@@ -60,11 +72,226 @@ record2</textarea>
 }
 
 func TestClientGetRecord(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "127.0.0.1")
+
+	client := newTestClient(t, testServer)
+
+	record := ddnsnow.Record{
+		Type:  ddnsnow.RecordTypeA,
+		Value: "127.0.0.1",
+	}
+	r, err := client.GetRecord(context.Background(), record)
+	if err != nil {
+		t.Fatalf("GetRecord: %v", err)
+	}
+	if r != record {
+		t.Fatalf("unexpected record: %v", r)
+	}
+}
+
+func TestClientCreateRecord(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "127.0.0.1")
+
+	client := newTestClient(t, testServer)
+
+	record := ddnsnow.Record{
+		Type:  ddnsnow.RecordTypeAAAA,
+		Value: "::1",
+	}
+	if err := client.CreateRecord(context.Background(), record); err != nil {
+		t.Fatalf("CreateRecord: %v", err)
+	}
+
+	if got := testServer.Field("update_data_a"); got != "127.0.0.1" {
+		t.Fatalf("unexpected update_data_a: %q", got)
+	}
+	if got := testServer.Field("update_data_aaaa"); got != "::1" {
+		t.Fatalf("unexpected update_data_aaaa: %q", got)
+	}
+}
+
+func TestClientCreateRecordFailsWithConflictedRecordExists(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "127.0.0.1")
+
+	client := newTestClient(t, testServer)
+
+	record := ddnsnow.Record{
+		Type:  ddnsnow.RecordTypeCNAME,
+		Value: "example.com",
+	}
+	if err := client.CreateRecord(context.Background(), record); !errors.Is(err, ddnsnow.ErrCNAMEConflict) {
+		t.Fatalf("CreateRecord: expected %v, got %v", ddnsnow.ErrCNAMEConflict, err)
+	}
+	if posts := testServer.Requests(http.MethodPost); posts != 0 {
+		t.Fatalf("unexpected number of updates: %d", posts)
+	}
+}
+
+func TestClientUpdateRecord(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "127.0.0.1")
+
+	client := newTestClient(t, testServer)
+
+	oldRecord := ddnsnow.Record{
+		Type:  ddnsnow.RecordTypeA,
+		Value: "127.0.0.1",
+	}
+	newRecord := ddnsnow.Record{
+		Type:  ddnsnow.RecordTypeA,
+		Value: "127.0.0.2",
+	}
+	if err := client.UpdateRecord(context.Background(), oldRecord, newRecord); err != nil {
+		t.Fatalf("UpdateRecord: %v", err)
+	}
+
+	if got := testServer.Field("update_data_a"); got != "127.0.0.2" {
+		t.Fatalf("unexpected update_data_a: %q", got)
+	}
+}
+
+func TestClientDeleteRecord(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "127.0.0.1")
+	testServer.SetRecords(ddnsnow.RecordTypeTXT, "record")
+
+	client := newTestClient(t, testServer)
+
+	record := ddnsnow.Record{
+		Type:  ddnsnow.RecordTypeA,
+		Value: "127.0.0.1",
+	}
+	if err := client.DeleteRecord(context.Background(), record); err != nil {
+		t.Fatalf("DeleteRecord: %v", err)
+	}
+
+	if got := testServer.Field("update_data_a"); got != "" {
+		t.Fatalf("unexpected update_data_a: %q", got)
+	}
+	if got := testServer.Field("update_data_txt"); got != "record" {
+		t.Fatalf("unexpected update_data_txt: %q", got)
+	}
+}
+
+func TestClientDeleteRecordFailsWhenRecordDoesNotExist(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "127.0.0.1")
+
+	client := newTestClient(t, testServer)
+
+	record := ddnsnow.Record{
+		Type:  ddnsnow.RecordTypeAAAA,
+		Value: "::1",
+	}
+	if err := client.DeleteRecord(context.Background(), record); !errors.Is(err, ddnsnow.ErrNotFound) {
+		t.Fatalf("DeleteRecord: expected %v, got %v", ddnsnow.ErrNotFound, err)
+	}
+}
+
+func TestClientDeleteRecordFailsForOtherValue(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "127.0.0.1")
+
+	client := newTestClient(t, testServer)
+
+	record := ddnsnow.Record{
+		Type:  ddnsnow.RecordTypeA,
+		Value: "127.0.0.2",
+	}
+	if err := client.DeleteRecord(context.Background(), record); !errors.Is(err, ddnsnow.ErrNotFound) {
+		t.Fatalf("DeleteRecord: expected %v, got %v", ddnsnow.ErrNotFound, err)
+	}
+	if got := testServer.Records(ddnsnow.RecordTypeA); !slices.Equal(got, []string{"127.0.0.1"}) {
+		t.Fatalf("unexpected A records: %v", got)
+	}
+}
+
+func TestClientGetSettingsAbortsWhenContextIsDone(t *testing.T) {
+	release := make(chan struct{})
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer testServer.Close()
+	defer close(release)
+	server := testServer.URL
+
+	client, err := ddnsnow.NewClient(&domain, &passwordHash, &server)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetSettings(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GetSettings: expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestClientCreateRecordKeepsConcurrentlyCreatedRecords(t *testing.T) {
+	// The latency widens the window between the read and the update of
+	// other writers.
+	testServer := newTestServer(ddnsnowtest.WithLatency(time.Millisecond))
+	defer testServer.Close()
+
+	client := newTestClient(t, testServer)
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			record := ddnsnow.Record{
+				Type:  ddnsnow.RecordTypeTXT,
+				Value: fmt.Sprintf("record%d", i),
+			}
+			if err := client.CreateRecord(context.Background(), record); err != nil {
+				t.Errorf("CreateRecord: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if records := testServer.Records(ddnsnow.RecordTypeTXT); len(records) != n {
+		t.Fatalf("unexpected records: %v", records)
+	}
+}
+
+func TestClientCreateRecordRetriesOnTopOfOutOfBandChanges(t *testing.T) {
+	var gets int
 	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			_, err := w.Write([]byte(`<html><input type="text" id="update_data_a" value="127.0.0.1"></html>`))
-			if err != nil {
+			gets++
+			txt := "record1"
+			if gets > 1 {
+				// Someone edits the domain in the web UI after our first read.
+				txt = "record1\nhuman"
+			}
+			if _, err := w.Write([]byte(`<html><textarea id="update_data_txt">` + txt + `</textarea></html>`)); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+		case http.MethodPost:
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("ParseForm: %v", err)
+			}
+			if v := r.PostForm.Get("update_data_txt"); v != "record1\nhuman\nrecord2" {
+				t.Fatalf("unexpected update_data_txt: %q", v)
+			}
+			if _, err := w.Write([]byte(`{"result":"OK"}`)); err != nil {
 				t.Fatalf("Write: %v", err)
 			}
 		}
@@ -78,76 +305,473 @@ func TestClientGetRecord(t *testing.T) {
 	}
 
 	record := ddnsnow.Record{
-		Type:  ddnsnow.RecordTypeA,
-		Value: "127.0.0.1",
+		Type:  ddnsnow.RecordTypeTXT,
+		Value: "record2",
 	}
-	r, err := client.GetRecord(record)
-	if err != nil {
+	if err := client.CreateRecord(context.Background(), record); err != nil {
 		t.Fatalf("CreateRecord: %v", err)
 	}
-	if r != record {
-		t.Fatalf("unexpected record: %v", r)
-	}
 }
 
-func TestClientCreateRecord(t *testing.T) {
+func TestClientCreateRecordFailsWithConflictWhenSettingsKeepChanging(t *testing.T) {
+	var gets int
 	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			_, err := w.Write([]byte(`<html><input type="text" id="update_data_a" value="127.0.0.1"></html>`))
-			if err != nil {
+			gets++
+			body := fmt.Sprintf(`<html><textarea id="update_data_txt">human%d</textarea></html>`, gets)
+			if _, err := w.Write([]byte(body)); err != nil {
 				t.Fatalf("Write: %v", err)
 			}
 		case http.MethodPost:
-			defer r.Body.Close()
-			var body []byte
-			var err error
-			if body, err = io.ReadAll(r.Body); err != nil {
-				t.Fatalf("ReadAll: %v", err)
-			}
+			t.Fatalf("unexpected POST")
+		}
+	}))
+	defer testServer.Close()
+	server := testServer.URL
 
-			values, err := url.ParseQuery(string(body))
-			if err != nil {
-				t.Fatalf("ParseQuery: %v", err)
-			}
-			if values.Get("action") != "update" ||
-				values.Get("json") != "1" ||
-				values.Get("update_data_a") != "127.0.0.1" ||
-				values.Get("update_data_aaaa") != "::1" {
-				t.Fatalf("unexpected values: %v", values)
-			}
+	client, err := ddnsnow.NewClient(&domain, &passwordHash, &server, ddnsnow.WithConflictRetries(1))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	record := ddnsnow.Record{
+		Type:  ddnsnow.RecordTypeTXT,
+		Value: "record",
+	}
+	err = client.CreateRecord(context.Background(), record)
+	var conflictErr *ddnsnow.ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("CreateRecord: expected ConflictError, got %v", err)
+	}
+	if conflictErr.Attempts != 2 {
+		t.Fatalf("unexpected attempts: %d", conflictErr.Attempts)
+	}
+}
+
+var testRetryPolicy = ddnsnow.RetryPolicy{
+	MaxAttempts:          3,
+	BaseBackoff:          time.Millisecond,
+	MaxBackoff:           time.Millisecond,
+	RetryableStatusCodes: []int{http.StatusBadGateway},
+	RetryableErrorCodes:  []int{99},
+}
+
+func TestClientGetSettingsRetriesTransientStatus(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "127.0.0.1")
+	testServer.InjectFault(ddnsnowtest.Fault{Times: 2, StatusCode: http.StatusBadGateway})
+
+	client := newTestClient(t, testServer, ddnsnow.WithRetryPolicy(testRetryPolicy))
 
-			_, err = w.Write([]byte(`{"result":"OK"}`))
+	settings, err := client.GetSettings(context.Background())
+	if err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+	if settings.Records[ddnsnow.RecordTypeA][0] != "127.0.0.1" {
+		t.Fatalf("unexpected record value: %s", settings.Records[ddnsnow.RecordTypeA][0])
+	}
+	if gets := testServer.Requests(http.MethodGet); gets != 3 {
+		t.Fatalf("unexpected number of requests: %d", gets)
+	}
+}
+
+func TestClientGetSettingsRetriesClosedConnections(t *testing.T) {
+	gets := 0
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gets++
+		if gets == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
 			if err != nil {
-				t.Fatalf("Write: %v", err)
+				t.Errorf("Hijack: %v", err)
+				return
 			}
+			conn.Close()
+			return
+		}
+		if _, err := w.Write([]byte(`<html><input type="text" id="update_data_a" value="127.0.0.1"></html>`)); err != nil {
+			t.Errorf("Write: %v", err)
 		}
 	}))
 	defer testServer.Close()
 	server := testServer.URL
 
+	client, err := ddnsnow.NewClient(&domain, &passwordHash, &server, ddnsnow.WithRetryPolicy(testRetryPolicy))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	if _, err := client.GetSettings(context.Background()); err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+	if gets != 2 {
+		t.Fatalf("unexpected number of requests: %d", gets)
+	}
+}
+
+func TestClientGetSettingsDoesNotRetryInvalidServerURL(t *testing.T) {
+	server := "f5.si"
+	policy := testRetryPolicy
+	policy.BaseBackoff = time.Second
+	policy.MaxBackoff = time.Second
+
+	client, err := ddnsnow.NewClient(&domain, &passwordHash, &server, ddnsnow.WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := client.GetSettings(context.Background()); err == nil {
+		t.Fatalf("GetSettings: expected an error")
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Fatalf("the permanent error was retried for %s", elapsed)
+	}
+}
+
+func TestClientGetSettingsDoesNotRetryUnknownHost(t *testing.T) {
+	// The .invalid top-level domain never resolves
+	server := "https://ddnsnow.invalid"
+	policy := testRetryPolicy
+	policy.BaseBackoff = time.Second
+	policy.MaxBackoff = time.Second
+
+	client, err := ddnsnow.NewClient(&domain, &passwordHash, &server, ddnsnow.WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := client.GetSettings(context.Background()); err == nil {
+		t.Fatalf("GetSettings: expected an error")
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Fatalf("the permanent error was retried for %s", elapsed)
+	}
+}
+
+func TestClientGetSettingsFailsWithPermanentStatus(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.InjectFault(ddnsnowtest.Fault{StatusCode: http.StatusNotFound})
+
+	client := newTestClient(t, testServer, ddnsnow.WithRetryPolicy(testRetryPolicy))
+
+	_, err := client.GetSettings(context.Background())
+	var statusErr *ddnsnow.HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("GetSettings: expected HTTPStatusError, got %v", err)
+	}
+	if gets := testServer.Requests(http.MethodGet); gets != 1 {
+		t.Fatalf("unexpected number of requests: %d", gets)
+	}
+}
+
+func TestClientCreateRecordRetriesRetryableAPIError(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.InjectFault(ddnsnowtest.Fault{Method: http.MethodPost, Times: 1, ErrorCode: 99, ErrorMsg: "busy"})
+
+	client := newTestClient(t, testServer, ddnsnow.WithRetryPolicy(testRetryPolicy))
+
+	record := ddnsnow.Record{
+		Type:  ddnsnow.RecordTypeA,
+		Value: "127.0.0.1",
+	}
+	if err := client.CreateRecord(context.Background(), record); err != nil {
+		t.Fatalf("CreateRecord: %v", err)
+	}
+	if posts := testServer.Requests(http.MethodPost); posts != 2 {
+		t.Fatalf("unexpected number of requests: %d", posts)
+	}
+	if got := testServer.Field("update_data_a"); got != "127.0.0.1" {
+		t.Fatalf("unexpected update_data_a: %q", got)
+	}
+}
+
+func TestClientGetSettingsRespectsRateLimit(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+
+	client := newTestClient(t, testServer, ddnsnow.WithRateLimit(20, 2))
+
+	// The burst covers the first 2 requests, the remaining 4 take 50ms each.
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if _, err := client.GetSettings(context.Background()); err != nil {
+			t.Fatalf("GetSettings: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("requests were not rate limited: %v", elapsed)
+	}
+}
+
+func TestClientGetSettingsHonoursRetryAfter(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.InjectFault(ddnsnowtest.Fault{Times: 1, StatusCode: http.StatusBadGateway, RetryAfter: "1"})
+
+	client := newTestClient(t, testServer, ddnsnow.WithRetryPolicy(testRetryPolicy))
+
+	start := time.Now()
+	if _, err := client.GetSettings(context.Background()); err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("Retry-After was not honoured: %v", elapsed)
+	}
+}
+
+func TestClientCreateRecordFailsWithAPIError(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.InjectFault(ddnsnowtest.Fault{Method: http.MethodPost, ErrorCode: 1, ErrorMsg: "invalid"})
+
+	client := newTestClient(t, testServer)
+
+	record := ddnsnow.Record{
+		Type:  ddnsnow.RecordTypeA,
+		Value: "127.0.0.1",
+	}
+	err := client.CreateRecord(context.Background(), record)
+	var apiErr *ddnsnow.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("CreateRecord: expected APIError, got %v", err)
+	}
+	if apiErr.Code != 1 || apiErr.Msg != "invalid" {
+		t.Fatalf("unexpected error: %v", apiErr)
+	}
+}
+
+func TestClientGetRecordFailsWithUnauthorized(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.InjectFault(ddnsnowtest.Fault{StatusCode: http.StatusUnauthorized})
+
+	client := newTestClient(t, testServer)
+
+	record := ddnsnow.Record{
+		Type:  ddnsnow.RecordTypeA,
+		Value: "127.0.0.1",
+	}
+	if _, err := client.GetRecord(context.Background(), record); !errors.Is(err, ddnsnow.ErrUnauthorized) {
+		t.Fatalf("GetRecord: expected %v, got %v", ddnsnow.ErrUnauthorized, err)
+	}
+}
+
+func TestClientGetRecordFailsWithUnauthorizedOnWrongPasswordHash(t *testing.T) {
+	testServer := ddnsnowtest.NewServer(ddnsnowtest.WithCredentials(domain, "fedcba9876543210"))
+	defer testServer.Close()
+
+	client := newTestClient(t, testServer)
+
+	record := ddnsnow.Record{
+		Type:  ddnsnow.RecordTypeA,
+		Value: "127.0.0.1",
+	}
+	if _, err := client.GetRecord(context.Background(), record); !errors.Is(err, ddnsnow.ErrUnauthorized) {
+		t.Fatalf("GetRecord: expected %v, got %v", ddnsnow.ErrUnauthorized, err)
+	}
+}
+
+func TestClientSetWildcard(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "127.0.0.1")
+
+	client := newTestClient(t, testServer)
+
+	if err := client.SetWildcard(context.Background(), true); err != nil {
+		t.Fatalf("SetWildcard: %v", err)
+	}
+
+	if !testServer.Wildcard() {
+		t.Fatalf("wildcard was not enabled")
+	}
+	if got := testServer.Field("update_data_a"); got != "127.0.0.1" {
+		t.Fatalf("unexpected update_data_a: %q", got)
+	}
+}
+
+func TestClientSetSettings(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeA, "127.0.0.1")
+	testServer.SetRecords(ddnsnow.RecordTypeTXT, "other")
+
+	client := newTestClient(t, testServer)
+
+	settings := &ddnsnow.Settings{
+		Records: map[ddnsnow.RecordType][]string{
+			ddnsnow.RecordTypeAAAA: {"::1"},
+			ddnsnow.RecordTypeTXT:  {"record1", "record2"},
+		},
+		EnableWildcard: true,
+	}
+	if err := client.SetSettings(context.Background(), settings); err != nil {
+		t.Fatalf("SetSettings: %v", err)
+	}
+
+	for key, want := range map[string]string{
+		"update_data_a":        "",
+		"update_data_aaaa":     "::1",
+		"update_data_txt":      "record1\nrecord2",
+		"update_data_wildcard": "1",
+	} {
+		if got := testServer.Field(key); got != want {
+			t.Fatalf("unexpected %s: %q", key, got)
+		}
+	}
+}
+
+func TestClientSetSettingsFailsWithCNAMEConflict(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected request: %s", r.Method)
+	}))
+	defer testServer.Close()
+	server := testServer.URL
+
 	client, err := ddnsnow.NewClient(&domain, &passwordHash, &server)
 	if err != nil {
 		t.Fatalf("NewClient: %v", err)
 	}
 
+	settings := &ddnsnow.Settings{
+		Records: map[ddnsnow.RecordType][]string{
+			ddnsnow.RecordTypeCNAME: {"example.com"},
+			ddnsnow.RecordTypeTXT:   {"record"},
+		},
+	}
+	if err := client.SetSettings(context.Background(), settings); !errors.Is(err, ddnsnow.ErrCNAMEConflict) {
+		t.Fatalf("SetSettings: expected %v, got %v", ddnsnow.ErrCNAMEConflict, err)
+	}
+}
+
+func TestClientUpdateRecords(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeTXT, "other", "record1", "record2")
+
+	client := newTestClient(t, testServer)
+
+	remove := []ddnsnow.Record{
+		{Type: ddnsnow.RecordTypeTXT, Value: "record1"},
+		{Type: ddnsnow.RecordTypeTXT, Value: "missing"},
+	}
+	add := []ddnsnow.Record{
+		{Type: ddnsnow.RecordTypeTXT, Value: "record3"},
+	}
+	if err := client.UpdateRecords(context.Background(), remove, add); err != nil {
+		t.Fatalf("UpdateRecords: %v", err)
+	}
+
+	if got := testServer.Field("update_data_txt"); got != "other\nrecord2\nrecord3" {
+		t.Fatalf("unexpected update_data_txt: %q", got)
+	}
+}
+
+func TestClientUpdateRecordsFailsWhenValueExists(t *testing.T) {
+	testServer := newTestServer()
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeTXT, "other")
+
+	client := newTestClient(t, testServer)
+
+	add := []ddnsnow.Record{
+		{Type: ddnsnow.RecordTypeTXT, Value: "record"},
+		{Type: ddnsnow.RecordTypeTXT, Value: "other"},
+	}
+	err := client.UpdateRecords(context.Background(), nil, add)
+	if !errors.Is(err, ddnsnow.ErrAlreadyExists) {
+		t.Fatalf("UpdateRecords: expected %v, got %v", ddnsnow.ErrAlreadyExists, err)
+	}
+
+	if got := testServer.Field("update_data_txt"); got != "other" {
+		t.Fatalf("unexpected update_data_txt: %q", got)
+	}
+}
+
+func TestClientSendsPasswordHashCookie(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cookie := r.Header.Get("Cookie"); cookie != "cookie_loginuser=domain%3Dtestdomain%3Bpassword_hash%3D0123456789abcdef%3B" {
+			t.Errorf("unexpected cookie: %s", cookie)
+		}
+		if _, err := w.Write([]byte(`<html><input type="text" id="update_data_a" value="127.0.0.1"></html>`)); err != nil {
+			t.Errorf("Write: %v", err)
+		}
+	}))
+	defer testServer.Close()
+	server := testServer.URL
+
+	client, err := ddnsnow.NewClient(&domain, &passwordHash, &server)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	if _, err := client.GetSettings(context.Background()); err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+}
+
+func TestClientLogsInWithPassword(t *testing.T) {
+	testServer := newTestServer(ddnsnowtest.WithPassword("secret"))
+	defer testServer.Close()
+
+	client := newTestClient(t, testServer, ddnsnow.WithPassword("secret"))
+
+	if _, err := client.GetSettings(context.Background()); err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+	if _, err := client.GetSettings(context.Background()); err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+	if logins := testServer.Logins(); logins != 1 {
+		t.Fatalf("expected 1 login, got %d", logins)
+	}
+
+	// The session expires, so the client logs in again and resends the
+	// request.
+	testServer.ExpireSessions()
 	record := ddnsnow.Record{
 		Type:  ddnsnow.RecordTypeAAAA,
 		Value: "::1",
 	}
-	if err := client.CreateRecord(record); err != nil {
+	if err := client.CreateRecord(context.Background(), record); err != nil {
 		t.Fatalf("CreateRecord: %v", err)
 	}
+	if logins := testServer.Logins(); logins != 2 {
+		t.Fatalf("expected 2 logins, got %d", logins)
+	}
+	if got := testServer.Field("update_data_aaaa"); got != "::1" {
+		t.Fatalf("unexpected update_data_aaaa: %q", got)
+	}
 }
 
-func TestClientCreateRecordFailsWithConflictedRecordExists(t *testing.T) {
+func TestClientLoginFailsWithUnauthorized(t *testing.T) {
+	testServer := newTestServer(ddnsnowtest.WithPassword("secret"))
+	defer testServer.Close()
+
+	client := newTestClient(t, testServer, ddnsnow.WithPassword("wrong"))
+
+	if _, err := client.GetSettings(context.Background()); !errors.Is(err, ddnsnow.ErrUnauthorized) {
+		t.Fatalf("GetSettings: expected %v, got %v", ddnsnow.ErrUnauthorized, err)
+	}
+}
+
+// newLoginTestServer returns a server which hands out a new session on every
+// login with password, and only serves the control page for the latest
+// session. expire invalidates the latest session.
+const testLoginPage = `<html><form method="post" action="control.php">
+<input type="text" name="domain">
+<input type="password" name="password">
+</form></html>`
+
+func TestClientGetSettingsFailsWithUnauthorizedOnLoginPage(t *testing.T) {
 	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			_, err := w.Write([]byte(`<html><input type="text" id="update_data_a" value="127.0.0.1"></html>`))
-			if err != nil {
-				t.Fatalf("Write: %v", err)
-			}
+		if _, err := w.Write([]byte(testLoginPage)); err != nil {
+			t.Errorf("Write: %v", err)
 		}
 	}))
 	defer testServer.Close()
@@ -158,45 +782,44 @@ func TestClientCreateRecordFailsWithConflictedRecordExists(t *testing.T) {
 		t.Fatalf("NewClient: %v", err)
 	}
 
+	// The login page has no records, which must not read as a missing record
 	record := ddnsnow.Record{
-		Type:  ddnsnow.RecordTypeCNAME,
-		Value: "example.com",
+		Type:  ddnsnow.RecordTypeA,
+		Value: "127.0.0.1",
 	}
-	if err := client.CreateRecord(record); err == nil {
-		t.Fatalf("CreateRecord: expected error, got nil")
+	if _, err := client.GetRecord(context.Background(), record); !errors.Is(err, ddnsnow.ErrUnauthorized) || errors.Is(err, ddnsnow.ErrNotFound) {
+		t.Fatalf("GetRecord: expected %v, got %v", ddnsnow.ErrUnauthorized, err)
 	}
 }
 
-func TestClientUpdateRecord(t *testing.T) {
+func TestClientGetSettingsFailsOnUnrecognizedPage(t *testing.T) {
 	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			_, err := w.Write([]byte(`<html><input type="text" id="update_data_a" value="127.0.0.1"></html>`))
-			if err != nil {
-				t.Fatalf("Write: %v", err)
-			}
-		case http.MethodPost:
-			defer r.Body.Close()
-			var body []byte
-			var err error
-			if body, err = io.ReadAll(r.Body); err != nil {
-				t.Fatalf("ReadAll: %v", err)
-			}
+		if _, err := w.Write([]byte(`<html><p>Under maintenance</p></html>`)); err != nil {
+			t.Errorf("Write: %v", err)
+		}
+	}))
+	defer testServer.Close()
+	server := testServer.URL
 
-			values, err := url.ParseQuery(string(body))
-			if err != nil {
-				t.Fatalf("ParseQuery: %v", err)
-			}
-			if values.Get("action") != "update" ||
-				values.Get("json") != "1" ||
-				values.Get("update_data_a") != "127.0.0.2" {
-				t.Fatalf("unexpected values: %v", values)
-			}
+	client, err := ddnsnow.NewClient(&domain, &passwordHash, &server)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
 
-			_, err = w.Write([]byte(`{"result":"OK"}`))
-			if err != nil {
-				t.Fatalf("Write: %v", err)
-			}
+	// A page without the login form must not read as rejected credentials
+	if _, err := client.GetSettings(context.Background()); !errors.Is(err, ddnsnow.ErrUnrecognizedPage) || errors.Is(err, ddnsnow.ErrUnauthorized) {
+		t.Fatalf("GetSettings: expected %v, got %v", ddnsnow.ErrUnrecognizedPage, err)
+	}
+}
+
+func TestClientCreateRecordFailsWithUnauthorizedOnLoginPage(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := `<html><input type="text" id="update_data_a" value=""></html>`
+		if r.Method == http.MethodPost {
+			body = testLoginPage
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Errorf("Write: %v", err)
 		}
 	}))
 	defer testServer.Close()
@@ -207,48 +830,149 @@ func TestClientUpdateRecord(t *testing.T) {
 		t.Fatalf("NewClient: %v", err)
 	}
 
-	oldRecord := ddnsnow.Record{
+	record := ddnsnow.Record{
 		Type:  ddnsnow.RecordTypeA,
 		Value: "127.0.0.1",
 	}
-	newRecord := ddnsnow.Record{
-		Type:  ddnsnow.RecordTypeA,
-		Value: "127.0.0.2",
+	if err := client.CreateRecord(context.Background(), record); !errors.Is(err, ddnsnow.ErrUnauthorized) {
+		t.Fatalf("CreateRecord: expected %v, got %v", ddnsnow.ErrUnauthorized, err)
 	}
-	if err := client.UpdateRecord(oldRecord, newRecord); err != nil {
-		t.Fatalf("CreateRecord: %v", err)
+}
+
+func TestClientLogsInAgainOnLoginPage(t *testing.T) {
+	var mu sync.Mutex
+	var logins int
+	expired := false
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm: %v", err)
+		}
+		if r.PostForm.Get("action") == "login" {
+			logins++
+			expired = false
+			http.SetCookie(w, &http.Cookie{Name: "cookie_loginuser", Value: "session"})
+			return
+		}
+
+		// The first session expires right away, serving the login page
+		body := `<html><input type="text" id="update_data_a" value="127.0.0.1"></html>`
+		if expired || logins == 1 {
+			expired = true
+			body = testLoginPage
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Errorf("Write: %v", err)
+		}
+	}))
+	defer testServer.Close()
+	server := testServer.URL
+	noHash := ""
+
+	client, err := ddnsnow.NewClient(&domain, &noHash, &server, ddnsnow.WithPassword("secret"))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	if _, err := client.GetSettings(context.Background()); err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+	if logins != 2 {
+		t.Fatalf("expected 2 logins, got %d", logins)
 	}
 }
 
-func TestClientDeleteRecord(t *testing.T) {
+func TestClientCreateRecordSubmitsHiddenFields(t *testing.T) {
+	for name, tc := range map[string]struct {
+		page string
+		want url.Values
+	}{
+		"discovered": {
+			page: `<input type="hidden" name="ukey" value="UKEY@rotated"><input type="hidden" name="token" value="csrf">`,
+			want: url.Values{"ukey": {"UKEY@rotated"}, "token": {"csrf"}},
+		},
+		"fallback": {
+			page: ``,
+			want: url.Values{"ukey": {"UKEY@061e10718b1455b638af4a55a8377a01"}},
+		},
+		"unnamed": {
+			page: `<input type="hidden" id="nameless" value="skipped">`,
+			want: url.Values{"nameless": nil},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					if _, err := w.Write([]byte(`<html><form>` + tc.page + `<input type="text" id="update_data_a" value=""></form></html>`)); err != nil {
+						t.Errorf("Write: %v", err)
+					}
+				case http.MethodPost:
+					if err := r.ParseForm(); err != nil {
+						t.Errorf("ParseForm: %v", err)
+					}
+					for key, want := range tc.want {
+						if got := r.PostForm[key]; strings.Join(got, ",") != strings.Join(want, ",") {
+							t.Errorf("unexpected %s: %v", key, got)
+						}
+					}
+					if r.PostForm.Get("action") != "update" || r.PostForm.Get("update_data_a") != "127.0.0.1" {
+						t.Errorf("unexpected values: %v", r.PostForm)
+					}
+					if _, err := w.Write([]byte(`{"result":"OK"}`)); err != nil {
+						t.Errorf("Write: %v", err)
+					}
+				}
+			}))
+			defer testServer.Close()
+			server := testServer.URL
+
+			client, err := ddnsnow.NewClient(&domain, &passwordHash, &server)
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+
+			record := ddnsnow.Record{
+				Type:  ddnsnow.RecordTypeA,
+				Value: "127.0.0.1",
+			}
+			if err := client.CreateRecord(context.Background(), record); err != nil {
+				t.Fatalf("CreateRecord: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientCreateRecordSubmitsOnlyTheSettingsForm(t *testing.T) {
 	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			_, err := w.Write([]byte(`<html><input type="text" id="update_data_a" value="127.0.0.1"></html>`))
-			if err != nil {
-				t.Fatalf("Write: %v", err)
+			if _, err := w.Write([]byte(`<html>
+<form><input type="hidden" name="action" value="logout"><input type="hidden" name="logout_token" value="x"></form>
+<form><input type="hidden" name="ukey" value="UKEY@rotated"><input type="text" id="update_data_a" value=""></form>
+<input type="text" name="search" value="orphan">
+</html>`)); err != nil {
+				t.Errorf("Write: %v", err)
 			}
 		case http.MethodPost:
-			defer r.Body.Close()
-			var body []byte
-			var err error
-			if body, err = io.ReadAll(r.Body); err != nil {
-				t.Fatalf("ReadAll: %v", err)
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("ParseForm: %v", err)
 			}
-
-			values, err := url.ParseQuery(string(body))
-			if err != nil {
-				t.Fatalf("ParseQuery: %v", err)
+			if got := r.PostForm["action"]; len(got) != 1 || got[0] != "update" {
+				t.Errorf("unexpected action: %v", got)
 			}
-			if values.Get("action") != "update" ||
-				values.Get("json") != "1" ||
-				values.Get("update_data_a") != "" {
-				t.Fatalf("unexpected values: %v", values)
+			if r.PostForm.Get("ukey") != "UKEY@rotated" || r.PostForm.Get("update_data_a") != "127.0.0.1" {
+				t.Errorf("unexpected values: %v", r.PostForm)
 			}
-
-			_, err = w.Write([]byte(`{"result":"OK"}`))
-			if err != nil {
-				t.Fatalf("Write: %v", err)
+			for _, key := range []string{"logout_token", "search"} {
+				if r.PostForm.Has(key) {
+					t.Errorf("unexpected %s: %v", key, r.PostForm[key])
+				}
+			}
+			if _, err := w.Write([]byte(`{"result":"OK"}`)); err != nil {
+				t.Errorf("Write: %v", err)
 			}
 		}
 	}))
@@ -264,18 +988,54 @@ func TestClientDeleteRecord(t *testing.T) {
 		Type:  ddnsnow.RecordTypeA,
 		Value: "127.0.0.1",
 	}
-	if err := client.DeleteRecord(record); err != nil {
+	if err := client.CreateRecord(context.Background(), record); err != nil {
 		t.Fatalf("CreateRecord: %v", err)
 	}
 }
 
-func TestClientDeleteRecordFailsWhenRecordDoesNotExist(t *testing.T) {
+func TestClientCreateRecordPreservesUnknownFields(t *testing.T) {
 	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			_, err := w.Write([]byte(`<html><input type="text" id="update_data_a" value="127.0.0.1"></html>`))
-			if err != nil {
-				t.Fatalf("Write: %v", err)
+			if _, err := w.Write([]byte(`<html><form>
+<input type="text" id="update_data_a" value="">
+<input type="text" name="update_data_mx" value="mail.example.com">
+<textarea name="update_data_memo">line1
+line2</textarea>
+<select name="update_data_ttl"><option value="60">1 min</option><option value="3600" selected>1 hour</option></select>
+<select name="update_data_mode"><option>simple</option><option>advanced</option></select>
+<input type="checkbox" name="update_data_ipv6" checked>
+<input type="checkbox" name="update_data_notify" value="1">
+<input type="radio" name="update_data_plan" value="free" checked>
+<input type="radio" name="update_data_plan" value="paid">
+<input type="text" name="update_data_locked" value="x" disabled>
+<input type="submit" name="update_button" value="Update">
+</form></html>`)); err != nil {
+				t.Errorf("Write: %v", err)
+			}
+		case http.MethodPost:
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("ParseForm: %v", err)
+			}
+			want := map[string]string{
+				"update_data_a":      "127.0.0.1",
+				"update_data_mx":     "mail.example.com",
+				"update_data_memo":   "line1\nline2",
+				"update_data_ttl":    "3600",
+				"update_data_mode":   "simple",
+				"update_data_ipv6":   "on",
+				"update_data_notify": "",
+				"update_data_plan":   "free",
+				"update_data_locked": "",
+				"update_button":      "",
+			}
+			for key, value := range want {
+				if r.PostForm.Get(key) != value {
+					t.Errorf("unexpected %s: %q", key, r.PostForm.Get(key))
+				}
+			}
+			if _, err := w.Write([]byte(`{"result":"OK"}`)); err != nil {
+				t.Errorf("Write: %v", err)
 			}
 		}
 	}))
@@ -288,10 +1048,10 @@ func TestClientDeleteRecordFailsWhenRecordDoesNotExist(t *testing.T) {
 	}
 
 	record := ddnsnow.Record{
-		Type:  ddnsnow.RecordTypeAAAA,
-		Value: "::1",
+		Type:  ddnsnow.RecordTypeA,
+		Value: "127.0.0.1",
 	}
-	if err := client.DeleteRecord(record); err == nil {
-		t.Fatalf("CreateRecord: expected error, got nil")
+	if err := client.CreateRecord(context.Background(), record); err != nil {
+		t.Fatalf("CreateRecord: %v", err)
 	}
 }
@@ -0,0 +1,476 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package ddnsnowtest provides an in-memory emulation of the DDNS Now control
+// page and update API for tests of code built on the ddnsnow package.
+package ddnsnowtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+)
+
+const (
+	// DefaultUsername is the username the server accepts unless configured
+	// otherwise.
+	DefaultUsername = "domain"
+	// DefaultPasswordHash is the password hash the server accepts unless
+	// configured otherwise.
+	DefaultPasswordHash = "0123456789abcdef0123456789abcdef"
+	// DefaultUKey is the ukey the server hands out unless configured
+	// otherwise.
+	DefaultUKey = "UKEY@ddnsnowtest"
+)
+
+// recordFields maps the record types to the fields of the control page
+// holding them.
+var recordFields = map[ddnsnow.RecordType]string{
+	ddnsnow.RecordTypeA:     "update_data_a",
+	ddnsnow.RecordTypeAAAA:  "update_data_aaaa",
+	ddnsnow.RecordTypeCNAME: "update_data_cname",
+	ddnsnow.RecordTypeTXT:   "update_data_txt",
+	ddnsnow.RecordTypeNS:    "update_data_ns",
+}
+
+// wildcardField is the checkbox of the control page enabling wildcard
+// resolution.
+const wildcardField = "update_data_wildcard"
+
+// Server is a stateful fake of the DDNS Now control page. Reads render the
+// current settings as HTML and updates replace them, like DDNS Now does.
+type Server struct {
+	*httptest.Server
+
+	username     string
+	passwordHash string
+	password     string
+	token        string
+	ukey         string
+	latency      time.Duration
+
+	mu       sync.Mutex
+	fields   url.Values
+	sessions map[string]bool
+	logins   int
+	faults   []*Fault
+	requests map[string]int
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithCredentials sets the username and password hash the server accepts.
+func WithCredentials(username, passwordHash string) Option {
+	return func(s *Server) {
+		s.username = username
+		s.passwordHash = passwordHash
+	}
+}
+
+// WithPassword enables logging in with password.
+func WithPassword(password string) Option {
+	return func(s *Server) {
+		s.password = password
+	}
+}
+
+// WithToken enables the update API, which accepts token.
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithUKey sets the ukey the control page hands out and updates must carry.
+func WithUKey(ukey string) Option {
+	return func(s *Server) {
+		s.ukey = ukey
+	}
+}
+
+// WithLatency delays every response by d.
+func WithLatency(d time.Duration) Option {
+	return func(s *Server) {
+		s.latency = d
+	}
+}
+
+// NewServer starts and returns a new Server holding no records. The caller
+// should call Close when finished, to shut it down.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		username:     DefaultUsername,
+		passwordHash: DefaultPasswordHash,
+		ukey:         DefaultUKey,
+		fields:       url.Values{},
+		sessions:     map[string]bool{},
+		requests:     map[string]int{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Fault describes a failure injected into the responses of the server.
+type Fault struct {
+	// Method restricts the fault to requests of the method, e.g.
+	// http.MethodPost. Empty matches every request.
+	Method string
+	// Times is the number of matching requests failing. Zero fails every
+	// matching request.
+	Times int
+	// StatusCode is the HTTP status to respond with.
+	StatusCode int
+	// ErrorCode and ErrorMsg make updates fail with a DDNS Now error when
+	// StatusCode is zero.
+	ErrorCode int
+	ErrorMsg  string
+	// RetryAfter is sent as the Retry-After header if not empty.
+	RetryAfter string
+}
+
+// InjectFault makes the server fail matching requests as described by f,
+// before authenticating them. Faults apply in the order they were injected.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &f)
+}
+
+// Requests returns the number of requests of method received so far. Empty
+// counts every request.
+func (s *Server) Requests(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if method == "" {
+		var n int
+		for _, count := range s.requests {
+			n += count
+		}
+		return n
+	}
+	return s.requests[method]
+}
+
+// Logins returns the number of successful logins with password so far.
+func (s *Server) Logins() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.logins
+}
+
+// ExpireSessions logs out every session obtained by logging in with
+// password.
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clear(s.sessions)
+}
+
+// Field returns the value of a field of the control page, e.g.
+// "update_data_a".
+func (s *Server) Field(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.fields.Get(key)
+}
+
+// SetField sets a field of the control page, as if changed in the web UI. An
+// empty value clears it.
+func (s *Server) SetField(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if value == "" {
+		s.fields.Del(key)
+		return
+	}
+	s.fields.Set(key, value)
+}
+
+// Records returns the values of the records of typ.
+func (s *Server) Records(typ ddnsnow.RecordType) []string {
+	value := s.Field(recordFields[typ])
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, "\n")
+}
+
+// SetRecords replaces the values of the records of typ.
+func (s *Server) SetRecords(typ ddnsnow.RecordType, values ...string) {
+	s.SetField(recordFields[typ], strings.Join(values, "\n"))
+}
+
+// Wildcard reports whether wildcard resolution is enabled.
+func (s *Server) Wildcard() bool {
+	return s.Field(wildcardField) != ""
+}
+
+// SetWildcard enables or disables wildcard resolution.
+func (s *Server) SetWildcard(enabled bool) {
+	value := ""
+	if enabled {
+		value = "1"
+	}
+	s.SetField(wildcardField, value)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.latency > 0 {
+		select {
+		case <-time.After(s.latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests[r.Method]++
+
+	if s.injectFault(w, r) {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.URL.Path == "/update.php" {
+		s.updateAddresses(w, r)
+		return
+	}
+
+	if r.Method == http.MethodPost && r.PostForm.Get("action") == "login" {
+		s.login(w, r)
+		return
+	}
+
+	if !s.authenticated(r) {
+		s.writeLoginPage(w)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.writeControlPage(w)
+	case http.MethodPost:
+		s.update(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// injectFault responds according to the first fault matching r, and reports
+// whether there was one.
+func (s *Server) injectFault(w http.ResponseWriter, r *http.Request) bool {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = slices.Delete(s.faults, i, i+1)
+			}
+		}
+
+		if f.RetryAfter != "" {
+			w.Header().Set("Retry-After", f.RetryAfter)
+		}
+		if f.StatusCode != 0 {
+			w.WriteHeader(f.StatusCode)
+			return true
+		}
+		writeResponse(w, "NG", f.ErrorCode, f.ErrorMsg, "")
+		return true
+	}
+
+	return false
+}
+
+// hashSession returns the session cookie value derived from the password
+// hash.
+func (s *Server) hashSession() string {
+	return url.QueryEscape("domain=" + s.username + ";password_hash=" + s.passwordHash + ";")
+}
+
+func (s *Server) authenticated(r *http.Request) bool {
+	cookie, err := r.Cookie("cookie_loginuser")
+	if err != nil {
+		return false
+	}
+	return cookie.Value == s.hashSession() || s.sessions[cookie.Value]
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	if s.password == "" || r.PostForm.Get("domain") != s.username || r.PostForm.Get("password") != s.password {
+		s.writeLoginPage(w)
+		return
+	}
+
+	s.logins++
+	session := fmt.Sprintf("session%d", s.logins)
+	s.sessions[session] = true
+	http.SetCookie(w, &http.Cookie{Name: "cookie_loginuser", Value: session, Path: "/"})
+	s.writeControlPage(w)
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.PostForm.Get("action") != "update":
+		writeResponse(w, "NG", 1, "unknown action", "")
+		return
+	case r.PostForm.Get("ukey") != s.ukey:
+		writeResponse(w, "NG", 2, "invalid ukey", "")
+		return
+	}
+
+	// The submission replaces every setting, so fields left out are
+	// cleared.
+	s.fields = url.Values{}
+	for key, values := range r.PostForm {
+		switch key {
+		case "action", "json", "ukey":
+			continue
+		}
+		if len(values) > 0 && values[0] != "" {
+			s.fields[key] = slices.Clone(values)
+		}
+	}
+
+	remoteIP, _, _ := net.SplitHostPort(r.RemoteAddr)
+	writeResponse(w, "OK", 0, "", remoteIP)
+}
+
+// updateAddresses implements the update API, which publishes the address
+// the request comes from unless told otherwise.
+func (s *Server) updateAddresses(w http.ResponseWriter, r *http.Request) {
+	if s.token == "" || r.Form.Get("domain") != s.username || r.Form.Get("password") != s.token {
+		writeResponse(w, "NG", 1, "invalid domain or password", "")
+		return
+	}
+
+	remoteIP, _, _ := net.SplitHostPort(r.RemoteAddr)
+	ipv4 := r.Form.Get("ip")
+	if ipv4 == "" {
+		ipv4 = remoteIP
+	}
+	if ip := net.ParseIP(ipv4); ip == nil || ip.To4() == nil {
+		writeResponse(w, "NG", 2, "invalid ip", remoteIP)
+		return
+	}
+	s.fields.Set(recordFields[ddnsnow.RecordTypeA], ipv4)
+
+	if ipv6 := r.Form.Get("ipv6"); ipv6 != "" {
+		if ip := net.ParseIP(ipv6); ip == nil || ip.To4() != nil {
+			writeResponse(w, "NG", 3, "invalid ipv6", remoteIP)
+			return
+		}
+		s.fields.Set(recordFields[ddnsnow.RecordTypeAAAA], ipv6)
+	}
+
+	writeResponse(w, "OK", 0, "", remoteIP)
+}
+
+func (s *Server) writeLoginPage(w http.ResponseWriter) {
+	writeHTML(w, `<html><body><form method="post" action="control.php">
+<input type="hidden" name="action" value="login">
+<input type="text" name="domain">
+<input type="password" name="password">
+<input type="submit" value="Login">
+</form></body></html>`)
+}
+
+func (s *Server) writeControlPage(w http.ResponseWriter) {
+	var body strings.Builder
+	body.WriteString(`<html><body><form method="post" action="control.php">` + "\n")
+	fmt.Fprintf(&body, `<input type="hidden" name="ukey" value="%s">`+"\n", html.EscapeString(s.ukey))
+
+	for _, typ := range ddnsnow.RecordTypes {
+		key := recordFields[typ]
+		value := html.EscapeString(s.fields.Get(key))
+		switch typ {
+		case ddnsnow.RecordTypeTXT, ddnsnow.RecordTypeNS:
+			fmt.Fprintf(&body, `<textarea id="%s" name="%s">%s</textarea>`+"\n", key, key, value)
+		default:
+			fmt.Fprintf(&body, `<input type="text" id="%s" name="%s" value="%s">`+"\n", key, key, value)
+		}
+	}
+
+	checked := ""
+	if s.fields.Get(wildcardField) != "" {
+		checked = " checked"
+	}
+	fmt.Fprintf(&body, `<input type="checkbox" id="%s" name="%s" value="1"%s>`+"\n", wildcardField, wildcardField, checked)
+
+	// Render the fields the emulation does not know about as text inputs,
+	// so that clients are expected to submit them back.
+	keys := make([]string, 0, len(s.fields))
+	for key := range s.fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if modelled(key) {
+			continue
+		}
+		fmt.Fprintf(&body, `<input type="text" name="%s" value="%s">`+"\n", html.EscapeString(key), html.EscapeString(s.fields.Get(key)))
+	}
+
+	body.WriteString(`<input type="submit" value="Update">` + "\n</form></body></html>")
+	writeHTML(w, body.String())
+}
+
+// modelled reports whether key is one of the fields the emulation renders
+// itself.
+func modelled(key string) bool {
+	if key == wildcardField {
+		return true
+	}
+	for _, field := range recordFields {
+		if field == key {
+			return true
+		}
+	}
+	return false
+}
+
+func writeHTML(w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(body))
+}
+
+// writeResponse writes a JSON response of the update API of the control
+// page.
+func writeResponse(w http.ResponseWriter, result string, code int, msg, remoteIP string) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"result":    result,
+		"errorcode": code,
+		"errormsg":  msg,
+		"remote_ip": remoteIP,
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ddnsnow
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrNotFound is returned when the record does not exist.
+	ErrNotFound = errors.New("ddnsnow: record not found")
+	// ErrAlreadyExists is returned when a single-valued record type already
+	// holds a value.
+	ErrAlreadyExists = errors.New("ddnsnow: record already exists")
+	// ErrCNAMEConflict is returned when a CNAME record would coexist with A,
+	// AAAA or TXT records.
+	ErrCNAMEConflict = errors.New("ddnsnow: CNAME record cannot coexist with A, AAAA or TXT records")
+	// ErrUnauthorized is returned when DDNS Now rejects the credentials.
+	ErrUnauthorized = errors.New("ddnsnow: unauthorized")
+	// ErrUnrecognizedPage is returned when the control page shows neither
+	// the settings of the domain nor the login form.
+	ErrUnrecognizedPage = errors.New("ddnsnow: unrecognized control page")
+)
+
+// ConflictError is returned when the settings of the domain keep changing
+// between reading them and submitting the modified settings.
+type ConflictError struct {
+	Attempts int
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("ddnsnow: settings were modified concurrently, gave up after %d attempt(s)", e.Attempts)
+}
+
+// HTTPStatusError is returned when DDNS Now responds with an HTTP error
+// status.
+type HTTPStatusError struct {
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("ddnsnow: unexpected HTTP status %d", e.StatusCode)
+}
+
+func (e *HTTPStatusError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	default:
+		return nil
+	}
+}
+
+// APIError is returned when DDNS Now rejects a request.
+type APIError struct {
+	Code int
+	Msg  string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("ddnsnow: code=%d, msg=%s", e.Code, e.Msg)
+}
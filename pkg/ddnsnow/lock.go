@@ -0,0 +1,45 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ddnsnow
+
+import (
+	"context"
+	"sync"
+)
+
+// domainLocks holds one lock per domain so that every client within the
+// process serializes its read-modify-write cycles against the same domain,
+// even when several provider instances are configured for it.
+var (
+	domainLocksMu sync.Mutex
+	domainLocks   = map[string]domainLock{}
+)
+
+// domainLock is a mutex which can be abandoned while waiting for it.
+type domainLock chan struct{}
+
+func lockFor(domain string) domainLock {
+	domainLocksMu.Lock()
+	defer domainLocksMu.Unlock()
+
+	l, ok := domainLocks[domain]
+	if !ok {
+		l = make(domainLock, 1)
+		domainLocks[domain] = l
+	}
+	return l
+}
+
+func (l domainLock) lock(ctx context.Context) error {
+	select {
+	case l <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l domainLock) unlock() {
+	<-l
+}
@@ -0,0 +1,148 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ddnsnow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// loginCookie is the cookie DDNS Now keeps the session of the domain in.
+const loginCookie = "cookie_loginuser"
+
+// WithPassword makes the client log in with the plain password of the domain
+// instead of using a password hash, and log in again whenever the session
+// expires. The password hash passed to NewClient is ignored.
+func WithPassword(password string) ClientOption {
+	return func(c *client) {
+		c.password = password
+	}
+}
+
+// session returns the value of the session cookie, or an empty string if
+// there is none.
+func (c *client) session() string {
+	for _, cookie := range c.httpClient.Jar.Cookies(&c.uiURL) {
+		if cookie.Name == loginCookie {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+// setSession replaces the session cookie. An empty value removes it.
+func (c *client) setSession(value string) {
+	cookie := &http.Cookie{
+		Name:  loginCookie,
+		Value: value,
+		Path:  "/",
+	}
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+	c.httpClient.Jar.SetCookies(&c.uiURL, []*http.Cookie{cookie})
+}
+
+// withSession runs request, logging in first if the client logs in with a
+// password and holds no session yet. If the session turns out to have
+// expired, it logs in again and runs request once more.
+func (c *client) withSession(ctx context.Context, request func() error) error {
+	if c.password == "" {
+		return request()
+	}
+
+	if err := c.ensureSession(ctx, ""); err != nil {
+		return err
+	}
+	used := c.session()
+
+	err := request()
+	if !errors.Is(err, ErrUnauthorized) {
+		return err
+	}
+
+	if err := c.ensureSession(ctx, used); err != nil {
+		return err
+	}
+	return request()
+}
+
+// ensureSession logs in unless the client already holds a session, or stale
+// is not the current session any more because another request renewed it in
+// the meantime.
+func (c *client) ensureSession(ctx context.Context, stale string) error {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	if current := c.session(); current != "" && current != stale {
+		return nil
+	}
+
+	return c.login(ctx)
+}
+
+// login submits the login form of DDNS Now, which sets the session cookie on
+// success.
+func (c *client) login(ctx context.Context) error {
+	c.setSession("")
+
+	body := url.Values{
+		"action":   {"login"},
+		"domain":   {c.username},
+		"password": {c.password},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.uiURL.String(), strings.NewReader(body.Encode()))
+	if err != nil {
+		return fmt.Errorf("http request construction: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	if c.session() == "" {
+		return fmt.Errorf("login: %w", ErrUnauthorized)
+	}
+
+	return nil
+}
+
+// isLoginPage reports whether body is an HTML page asking for a password.
+func isLoginPage(body []byte) bool {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+
+	return hasPasswordInput(doc)
+}
+
+// hasPasswordInput reports whether doc has an input for a password.
+func hasPasswordInput(doc *html.Node) bool {
+	for node := range doc.Descendants() {
+		if node.Type != html.ElementNode || node.Data != "input" {
+			continue
+		}
+		for _, attr := range node.Attr {
+			if attr.Key == "type" && strings.EqualFold(attr.Val, "password") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -13,7 +13,27 @@ var (
 	RecordTypeTXT   RecordType = "TXT"
 )
 
+// RecordTypes lists every supported record type.
+var RecordTypes = []RecordType{
+	RecordTypeA,
+	RecordTypeAAAA,
+	RecordTypeCNAME,
+	RecordTypeNS,
+	RecordTypeTXT,
+}
+
 type Record struct {
 	Type  RecordType
 	Value string
 }
+
+// ConflictsWith reports whether the records cannot be published together,
+// which is the case for a CNAME record along with A, AAAA or TXT records.
+func (r Record) ConflictsWith(other Record) bool {
+	coexisting := func(typ RecordType) bool {
+		return typ == RecordTypeA || typ == RecordTypeAAAA || typ == RecordTypeTXT
+	}
+
+	return r.Type == RecordTypeCNAME && coexisting(other.Type) ||
+		other.Type == RecordTypeCNAME && coexisting(r.Type)
+}
@@ -0,0 +1,32 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ddnsnow_test
+
+import (
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+	"testing"
+)
+
+func TestRecordConflictsWith(t *testing.T) {
+	cname := ddnsnow.Record{Type: ddnsnow.RecordTypeCNAME, Value: "example.com"}
+	tests := []struct {
+		other    ddnsnow.Record
+		conflict bool
+	}{
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeA, Value: "127.0.0.1"}, true},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeAAAA, Value: "::1"}, true},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeTXT, Value: "record"}, true},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeNS, Value: "ns1.example.com"}, false},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeCNAME, Value: "example.net"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := cname.ConflictsWith(tt.other); got != tt.conflict {
+			t.Errorf("ConflictsWith(%v): expected %t, got %t", tt.other, tt.conflict, got)
+		}
+		if got := tt.other.ConflictsWith(cname); got != tt.conflict {
+			t.Errorf("%v.ConflictsWith: expected %t, got %t", tt.other, tt.conflict, got)
+		}
+	}
+}
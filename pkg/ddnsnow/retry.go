@@ -0,0 +1,144 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ddnsnow
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/url"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures how requests failing with transient errors are
+// retried. Requests are only retried when sending them again is harmless.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	MaxAttempts int
+	// BaseBackoff is the wait before the first retry. It doubles on every
+	// further retry up to MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Jitter randomizes each wait between half and all of its length.
+	Jitter bool
+	// RetryableStatusCodes lists the HTTP status codes worth retrying.
+	RetryableStatusCodes []int
+	// RetryableErrorCodes lists the DDNS Now error codes worth retrying.
+	RetryableErrorCodes []int
+}
+
+// DefaultRetryPolicy returns the retry policy used unless configured
+// otherwise.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseBackoff: time.Second,
+		MaxBackoff:  30 * time.Second,
+		Jitter:      true,
+		RetryableStatusCodes: []int{
+			429, // Too Many Requests
+			500, // Internal Server Error
+			502, // Bad Gateway
+			503, // Service Unavailable
+			504, // Gateway Timeout
+		},
+	}
+}
+
+// WithRetryPolicy replaces the default retry policy of the client.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *client) {
+		c.retryPolicy = policy
+	}
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return slices.Contains(p.RetryableStatusCodes, statusErr.StatusCode)
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return slices.Contains(p.RetryableErrorCodes, apiErr.Code)
+	}
+
+	// Failures of the transport itself, e.g. reset connections. Invalid
+	// URLs and failed certificate verifications persist, so they are not
+	// worth waiting for.
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return false
+	}
+	return transientNetworkError(urlErr.Err)
+}
+
+// transientNetworkError reports whether err is a network failure which may
+// not happen again.
+func transientNetworkError(err error) bool {
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCertErr x509.CertificateInvalidError
+	if errors.As(err, &certErr) || errors.As(err, &unknownAuthorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidCertErr) {
+		return false
+	}
+
+	// A host which does not exist keeps not existing
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false
+	}
+
+	// *url.Error implements net.Error itself, so only its cause is checked.
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.BaseBackoff
+	for i := 1; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, p.MaxBackoff)
+
+	if p.Jitter && backoff > 1 {
+		backoff = backoff/2 + rand.N(backoff/2)
+	}
+
+	return backoff
+}
+
+// retry calls send until it succeeds, fails permanently or runs out of
+// attempts. Non-idempotent requests are never retried, as an attempt which
+// failed from the client's point of view may still have been applied.
+func (c *client) retry(ctx context.Context, idempotent bool, send func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := send()
+		if err == nil || !idempotent || ctx.Err() != nil {
+			return err
+		}
+		if attempt >= c.retryPolicy.MaxAttempts || !c.retryPolicy.retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(c.retryPolicy.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
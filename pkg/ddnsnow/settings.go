@@ -7,30 +7,49 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"slices"
 	"strings"
 
 	"golang.org/x/net/html"
 )
 
-type settings struct {
+// Settings holds everything configured for a domain on the control page.
+type Settings struct {
 	Records        map[RecordType][]string
 	EnableWildcard bool
+
+	// fields holds the other fields of the control page, e.g. the ukey and
+	// settings not modelled here, which are submitted back verbatim.
+	fields url.Values
+}
+
+// settingsFields lists the fields of the control page modelled by Settings.
+var settingsFields = []string{
+	"update_data_a",
+	"update_data_aaaa",
+	"update_data_cname",
+	"update_data_txt",
+	"update_data_ns",
+	"update_data_wildcard",
 }
 
-func parseSettings(r io.Reader) (*settings, error) {
+func parseSettings(r io.Reader) (*Settings, error) {
 	doc, err := html.Parse(r)
 	if err != nil {
 		return nil, fmt.Errorf("parse html: %w", err)
 	}
 
-	settings := settings{
+	settings := Settings{
 		Records: map[RecordType][]string{},
+		fields:  url.Values{},
 	}
+	form := settingsForm(doc)
+	found := false
 	for node := range doc.Descendants() {
 		if node.Type != html.ElementNode {
 			continue
 		}
-		if node.Data != "input" && node.Data != "textarea" {
+		if node.Data != "input" && node.Data != "textarea" && node.Data != "select" {
 			continue
 		}
 
@@ -39,9 +58,15 @@ func parseSettings(r io.Reader) (*settings, error) {
 			attributes[attr.Key] = attr.Val
 		}
 		key, ok := attributes["id"]
-		if !ok {
+		if !ok || !slices.Contains(settingsFields, key) {
+			// Other forms of the page, e.g. a logout button, must not be
+			// merged into the update.
+			if form != nil && contains(form, node) {
+				parseField(node, attributes, settings.fields)
+			}
 			continue
 		}
+		found = true
 
 		var recordType RecordType
 		switch key {
@@ -78,16 +103,148 @@ func parseSettings(r io.Reader) (*settings, error) {
 		}
 	}
 
+	// DDNS Now serves the login page instead of the control page when the
+	// session is not logged in, which must not read as a domain without
+	// records. Neither must a page which has changed beyond recognition.
+	if !found {
+		if hasPasswordInput(doc) {
+			return nil, fmt.Errorf("%w: DDNS Now responded with the login page", ErrUnauthorized)
+		}
+		return nil, ErrUnrecognizedPage
+	}
+
 	return &settings, nil
 }
 
-func (s *settings) getRecord(record Record) (Record, error) {
+// settingsForm returns the form holding the fields modelled by Settings, if
+// any.
+func settingsForm(doc *html.Node) *html.Node {
+	for node := range doc.Descendants() {
+		if node.Type != html.ElementNode || !slices.Contains(settingsFields, idOf(node)) {
+			continue
+		}
+		for parent := node.Parent; parent != nil; parent = parent.Parent {
+			if parent.Type == html.ElementNode && parent.Data == "form" {
+				return parent
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// idOf returns the id attribute of node.
+func idOf(node *html.Node) string {
+	for _, attr := range node.Attr {
+		if attr.Key == "id" {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// contains reports whether node is a descendant of ancestor.
+func contains(ancestor, node *html.Node) bool {
+	for parent := node.Parent; parent != nil; parent = parent.Parent {
+		if parent == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// parseField adds the values a browser would submit for a form field which
+// is not modelled by Settings to fields.
+func parseField(node *html.Node, attributes map[string]string, fields url.Values) {
+	// Browsers do not submit fields without a name
+	key := attributes["name"]
+	if _, disabled := attributes["disabled"]; key == "" || disabled {
+		return
+	}
+
+	switch node.Data {
+	case "input":
+		switch strings.ToLower(attributes["type"]) {
+		case "submit", "button", "image", "reset", "file":
+		case "checkbox", "radio":
+			if _, checked := attributes["checked"]; !checked {
+				return
+			}
+			value, ok := attributes["value"]
+			if !ok {
+				value = "on"
+			}
+			fields.Add(key, value)
+		default:
+			fields.Add(key, attributes["value"])
+		}
+
+	case "textarea":
+		var text strings.Builder
+		for child := range node.ChildNodes() {
+			if child.Type == html.TextNode {
+				text.WriteString(child.Data)
+			}
+		}
+		fields.Add(key, text.String())
+
+	case "select":
+		_, multiple := attributes["multiple"]
+		var first *html.Node
+		selected := false
+		for option := range node.Descendants() {
+			if option.Type != html.ElementNode || option.Data != "option" {
+				continue
+			}
+			if first == nil {
+				first = option
+			}
+			if optionAttribute(option, "selected") != nil {
+				fields.Add(key, optionValue(option))
+				selected = true
+			}
+		}
+		// Browsers submit the first option of a single select when none is
+		// selected explicitly.
+		if !selected && !multiple && first != nil {
+			fields.Add(key, optionValue(first))
+		}
+	}
+}
+
+// optionAttribute returns the attribute of option named key, if any.
+func optionAttribute(option *html.Node, key string) *html.Attribute {
+	for i := range option.Attr {
+		if option.Attr[i].Key == key {
+			return &option.Attr[i]
+		}
+	}
+	return nil
+}
+
+// optionValue returns the submitted value of option, which defaults to its
+// text.
+func optionValue(option *html.Node) string {
+	if attr := optionAttribute(option, "value"); attr != nil {
+		return attr.Val
+	}
+
+	var text strings.Builder
+	for child := range option.Descendants() {
+		if child.Type == html.TextNode {
+			text.WriteString(child.Data)
+		}
+	}
+	return strings.TrimSpace(text.String())
+}
+
+func (s *Settings) getRecord(record Record) (Record, error) {
 	records := s.Records[record.Type]
 
 	switch record.Type {
 	case RecordTypeA, RecordTypeAAAA, RecordTypeCNAME:
 		if len(records) == 0 {
-			return Record{}, fmt.Errorf("record not found: %s", record.Type)
+			return Record{}, fmt.Errorf("%w: %s", ErrNotFound, record.Type)
 		}
 		return Record{
 			Type:  record.Type,
@@ -101,18 +258,19 @@ func (s *settings) getRecord(record Record) (Record, error) {
 				return record, nil
 			}
 		}
-		return Record{}, fmt.Errorf("record not found: %s", record)
+		return Record{}, fmt.Errorf("%w: %s", ErrNotFound, record)
 
 	default:
 		return Record{}, fmt.Errorf("unsupported record type: %s", record.Type)
 	}
 }
 
-func (s *settings) removeRecord(record Record) error {
+func (s *Settings) removeRecord(record Record) error {
 	switch record.Type {
 	case RecordTypeA, RecordTypeAAAA, RecordTypeCNAME:
-		if len(s.Records[record.Type]) != 1 {
-			return fmt.Errorf("record not found: %s", record)
+		// Another value must not be removed in place of the given one
+		if len(s.Records[record.Type]) != 1 || s.Records[record.Type][0] != record.Value {
+			return fmt.Errorf("%w: %s", ErrNotFound, record)
 		}
 		delete(s.Records, record.Type)
 
@@ -127,7 +285,7 @@ func (s *settings) removeRecord(record Record) error {
 			}
 		}
 		if removed == 0 {
-			return fmt.Errorf("record not found: %s", record)
+			return fmt.Errorf("%w: %s", ErrNotFound, record)
 		}
 		s.Records[record.Type] = records
 	}
@@ -135,15 +293,19 @@ func (s *settings) removeRecord(record Record) error {
 	return nil
 }
 
-func (s *settings) addRecord(record Record) error {
+func (s *Settings) addRecord(record Record) error {
+	if err := record.Validate(); err != nil {
+		return err
+	}
+
 	switch record.Type {
 	case RecordTypeA, RecordTypeAAAA, RecordTypeTXT:
 		if len(s.Records[RecordTypeCNAME]) > 0 {
-			return fmt.Errorf("CNAME record already exists")
+			return fmt.Errorf("%w: CNAME record already exists", ErrCNAMEConflict)
 		}
 	case RecordTypeCNAME:
 		if len(s.Records[RecordTypeA]) > 0 || len(s.Records[RecordTypeAAAA]) > 0 || len(s.Records[RecordTypeTXT]) > 0 {
-			return fmt.Errorf("A/AAAA/TXT record already exists")
+			return fmt.Errorf("%w: A/AAAA/TXT record already exists", ErrCNAMEConflict)
 		}
 	}
 
@@ -152,7 +314,7 @@ func (s *settings) addRecord(record Record) error {
 		if len(s.Records[record.Type]) == 0 {
 			s.Records[record.Type] = []string{record.Value}
 		} else {
-			return fmt.Errorf("record already exists: %s", record)
+			return fmt.Errorf("%w: %s", ErrAlreadyExists, record)
 		}
 
 	case RecordTypeNS, RecordTypeTXT:
@@ -164,7 +326,50 @@ func (s *settings) addRecord(record Record) error {
 	return nil
 }
 
-func (s *settings) values() url.Values {
+// validate checks whether the settings can be submitted as a whole.
+func (s *Settings) validate() error {
+	for typ, records := range s.Records {
+		switch typ {
+		case RecordTypeA, RecordTypeAAAA, RecordTypeCNAME:
+			if len(records) > 1 {
+				return fmt.Errorf("%w: %s accepts only one value", ErrAlreadyExists, typ)
+			}
+		}
+		for _, value := range records {
+			if err := (Record{Type: typ, Value: value}).Validate(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(s.Records[RecordTypeCNAME]) > 0 &&
+		(len(s.Records[RecordTypeA]) > 0 || len(s.Records[RecordTypeAAAA]) > 0 || len(s.Records[RecordTypeTXT]) > 0) {
+		return fmt.Errorf("%w: A/AAAA/TXT record along with CNAME record", ErrCNAMEConflict)
+	}
+
+	return nil
+}
+
+// fingerprint identifies the settings as they would be submitted.
+func (s *Settings) fingerprint() string {
+	return s.values().Encode()
+}
+
+// submission returns the form submitting the settings, along with the other
+// fields of page, which is the latest read of the control page, so that
+// settings not modelled here are left untouched.
+func (s *Settings) submission(page *Settings) url.Values {
+	values := url.Values{}
+	for key, field := range page.fields {
+		values[key] = slices.Clone(field)
+	}
+	for key, value := range s.values() {
+		values[key] = value
+	}
+	return values
+}
+
+func (s *Settings) values() url.Values {
 	values := url.Values{}
 	for typ, records := range s.Records {
 		if len(records) == 0 {
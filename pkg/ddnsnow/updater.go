@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ddnsnow
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"net/url"
+)
+
+// Updater publishes the addresses of a host as the A and AAAA records of a
+// domain through the update API of DDNS Now. Unlike Client, it authenticates
+// with the API token of the domain and leaves every other setting untouched.
+type Updater struct {
+	client    *client
+	updateURL url.URL
+	domain    string
+	token     string
+}
+
+// NewUpdater returns an Updater for domain on server, which defaults to DDNS
+// Now. Of the options, only WithRetryPolicy and WithRateLimit apply.
+func NewUpdater(domain, token, server string, opts ...ClientOption) (*Updater, error) {
+	updateURL, err := serverURL(server, "/update.php")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &client{
+		httpClient:  &http.Client{},
+		retryPolicy: DefaultRetryPolicy(),
+		limiter:     newRateLimiter(0, 1),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return &Updater{
+		client:    c,
+		updateURL: *updateURL,
+		domain:    domain,
+		token:     token,
+	}, nil
+}
+
+// Addresses holds the addresses to publish. Invalid, i.e. zero, addresses
+// are not submitted.
+type Addresses struct {
+	// IPv4 is published as the A record. If invalid, DDNS Now publishes the
+	// address the request comes from instead.
+	IPv4 netip.Addr
+	// IPv6 is published as the AAAA record. If invalid, the AAAA record is
+	// left untouched.
+	IPv6 netip.Addr
+}
+
+// Update publishes addresses and returns the address DDNS Now saw the
+// request coming from.
+func (u *Updater) Update(ctx context.Context, addresses Addresses) (netip.Addr, error) {
+	query := url.Values{
+		"domain":   {u.domain},
+		"password": {u.token},
+		"format":   {"json"},
+	}
+	if addresses.IPv4.IsValid() {
+		if !addresses.IPv4.Is4() {
+			return netip.Addr{}, fmt.Errorf("%w: %s is not an IPv4 address", ErrInvalidRecord, addresses.IPv4)
+		}
+		query.Set("ip", addresses.IPv4.String())
+	}
+	if addresses.IPv6.IsValid() {
+		if !addresses.IPv6.Is6() || addresses.IPv6.Is4In6() || addresses.IPv6.Zone() != "" {
+			return netip.Addr{}, fmt.Errorf("%w: %s is not an IPv6 address", ErrInvalidRecord, addresses.IPv6)
+		}
+		query.Set("ipv6", addresses.IPv6.String())
+	}
+
+	updateURL := u.updateURL
+	updateURL.RawQuery = query.Encode()
+
+	// Publishing the same addresses again is harmless.
+	var remoteIP netip.Addr
+	err := u.client.retry(ctx, true, func() error {
+		req, err := http.NewRequestWithContext(ctx, "GET", updateURL.String(), nil)
+		if err != nil {
+			return fmt.Errorf("http request construction: %w", redactQuery(err))
+		}
+
+		resp, err := u.client.do(req)
+		if err != nil {
+			return err
+		}
+
+		ddnsNowResp, err := decodeResponse(resp)
+		if err != nil {
+			return err
+		}
+
+		remoteIP, err = netip.ParseAddr(ddnsNowResp.RemoteIP)
+		if err != nil {
+			return fmt.Errorf("parse remote_ip: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	return remoteIP, nil
+}
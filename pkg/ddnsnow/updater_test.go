@@ -0,0 +1,149 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ddnsnow_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/netip"
+	"strings"
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+	"terraform-provider-ddnsnow/pkg/ddnsnow/ddnsnowtest"
+	"testing"
+)
+
+const token = "token"
+
+func TestUpdaterUpdate(t *testing.T) {
+	testServer := newTestServer(ddnsnowtest.WithToken(token))
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeTXT, "record")
+
+	updater, err := ddnsnow.NewUpdater(domain, token, testServer.URL)
+	if err != nil {
+		t.Fatalf("NewUpdater: %v", err)
+	}
+
+	remoteIP, err := updater.Update(context.Background(), ddnsnow.Addresses{
+		IPv4: netip.MustParseAddr("192.0.2.1"),
+		IPv6: netip.MustParseAddr("2001:db8::1"),
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if remoteIP != netip.MustParseAddr("127.0.0.1") {
+		t.Fatalf("unexpected remote IP: %s", remoteIP)
+	}
+	for key, want := range map[string]string{
+		"update_data_a":    "192.0.2.1",
+		"update_data_aaaa": "2001:db8::1",
+		"update_data_txt":  "record",
+	} {
+		if got := testServer.Field(key); got != want {
+			t.Fatalf("unexpected %s: %q", key, got)
+		}
+	}
+}
+
+func TestUpdaterUpdatePublishesRemoteIP(t *testing.T) {
+	testServer := newTestServer(ddnsnowtest.WithToken(token))
+	defer testServer.Close()
+	testServer.SetRecords(ddnsnow.RecordTypeAAAA, "2001:db8::1")
+
+	updater, err := ddnsnow.NewUpdater(domain, token, testServer.URL)
+	if err != nil {
+		t.Fatalf("NewUpdater: %v", err)
+	}
+
+	remoteIP, err := updater.Update(context.Background(), ddnsnow.Addresses{})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if got := testServer.Field("update_data_a"); got != remoteIP.String() {
+		t.Fatalf("unexpected update_data_a: %q", got)
+	}
+	if got := testServer.Field("update_data_aaaa"); got != "2001:db8::1" {
+		t.Fatalf("unexpected update_data_aaaa: %q", got)
+	}
+}
+
+func TestUpdaterUpdateFailsWithAPIError(t *testing.T) {
+	testServer := newTestServer(ddnsnowtest.WithToken(token))
+	defer testServer.Close()
+
+	updater, err := ddnsnow.NewUpdater(domain, "wrong", testServer.URL)
+	if err != nil {
+		t.Fatalf("NewUpdater: %v", err)
+	}
+
+	_, err = updater.Update(context.Background(), ddnsnow.Addresses{})
+	var apiErr *ddnsnow.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Update: expected APIError, got %v", err)
+	}
+}
+
+func TestUpdaterUpdateRetriesTransientStatus(t *testing.T) {
+	testServer := newTestServer(ddnsnowtest.WithToken(token))
+	defer testServer.Close()
+	testServer.InjectFault(ddnsnowtest.Fault{Times: 1, StatusCode: http.StatusBadGateway})
+
+	updater, err := ddnsnow.NewUpdater(domain, token, testServer.URL, ddnsnow.WithRetryPolicy(testRetryPolicy))
+	if err != nil {
+		t.Fatalf("NewUpdater: %v", err)
+	}
+
+	if _, err := updater.Update(context.Background(), ddnsnow.Addresses{}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if gets := testServer.Requests(http.MethodGet); gets != 2 {
+		t.Fatalf("unexpected number of requests: %d", gets)
+	}
+}
+
+func TestUpdaterUpdateRejectsInvalidAddresses(t *testing.T) {
+	testServer := newTestServer(ddnsnowtest.WithToken(token))
+	defer testServer.Close()
+
+	updater, err := ddnsnow.NewUpdater(domain, token, testServer.URL)
+	if err != nil {
+		t.Fatalf("NewUpdater: %v", err)
+	}
+
+	for _, addresses := range []ddnsnow.Addresses{
+		{IPv4: netip.MustParseAddr("2001:db8::1")},
+		{IPv6: netip.MustParseAddr("192.0.2.1")},
+		{IPv6: netip.MustParseAddr("::ffff:192.0.2.1")},
+	} {
+		if _, err := updater.Update(context.Background(), addresses); !errors.Is(err, ddnsnow.ErrInvalidRecord) {
+			t.Fatalf("Update(%v): expected %v, got %v", addresses, ddnsnow.ErrInvalidRecord, err)
+		}
+	}
+	if requests := testServer.Requests(""); requests != 0 {
+		t.Fatalf("unexpected number of requests: %d", requests)
+	}
+}
+
+func TestUpdaterUpdateKeepsTokenOutOfErrors(t *testing.T) {
+	testServer := newTestServer(ddnsnowtest.WithToken(token))
+	server := testServer.URL
+	testServer.Close()
+
+	const secret = "SECRETTOKEN"
+	updater, err := ddnsnow.NewUpdater(domain, secret, server, ddnsnow.WithRetryPolicy(testRetryPolicy))
+	if err != nil {
+		t.Fatalf("NewUpdater: %v", err)
+	}
+
+	_, err = updater.Update(context.Background(), ddnsnow.Addresses{})
+	if err == nil {
+		t.Fatalf("Update: expected an error")
+	}
+	if strings.Contains(err.Error(), secret) {
+		t.Fatalf("the token leaks into the error: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ddnsnow
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// maxTXTLength is the maximum length of a single character-string in DNS.
+const maxTXTLength = 255
+
+// ErrInvalidRecord is returned when a record is malformed.
+var ErrInvalidRecord = errors.New("ddnsnow: invalid record")
+
+// Validate checks whether the record can be published.
+func (r Record) Validate() error {
+	if !slices.Contains(RecordTypes, r.Type) {
+		return fmt.Errorf("%w: unsupported record type %q", ErrInvalidRecord, r.Type)
+	}
+
+	var err error
+	switch r.Type {
+	case RecordTypeA:
+		err = validateIPAddress(r.Value, true)
+	case RecordTypeAAAA:
+		err = validateIPAddress(r.Value, false)
+	case RecordTypeCNAME, RecordTypeNS:
+		err = validateHostname(r.Value)
+	case RecordTypeTXT:
+		err = validateTXT(r.Value)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s record %q: %w", ErrInvalidRecord, r.Type, r.Value, err)
+	}
+
+	return nil
+}
+
+func validateIPAddress(value string, v4 bool) error {
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case v4 && !addr.Is4():
+		return errors.New("not an IPv4 address")
+	case !v4 && (!addr.Is6() || addr.Is4In6()):
+		return errors.New("not an IPv6 address")
+	case addr.Zone() != "":
+		return errors.New("zoned addresses are not allowed")
+	}
+
+	return nil
+}
+
+func validateHostname(value string) error {
+	name := strings.TrimSuffix(value, ".")
+	if name == "" {
+		return errors.New("empty hostname")
+	}
+	if len(name) > 253 {
+		return errors.New("hostname longer than 253 characters")
+	}
+
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 {
+			return fmt.Errorf("label %q must be 1 to 63 characters long", label)
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("label %q must not start or end with a hyphen", label)
+		}
+		// Targets are domain names rather than host names, which may hold
+		// underscores, e.g. _x.acm-validations.aws (RFC 2181, section 11).
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return fmt.Errorf("label %q must consist of letters, digits, hyphens and underscores", label)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateTXT(value string) error {
+	if value == "" {
+		return errors.New("empty value")
+	}
+	if len(value) > maxTXTLength {
+		return fmt.Errorf("longer than %d characters", maxTXTLength)
+	}
+
+	for _, c := range value {
+		// Line breaks would split the value into several records.
+		if c < ' ' || c > '~' {
+			return fmt.Errorf("character %q is not printable ASCII", c)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ddnsnow_test
+
+import (
+	"errors"
+	"strings"
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+	"testing"
+)
+
+func TestRecordValidate(t *testing.T) {
+	tests := []struct {
+		record ddnsnow.Record
+		valid  bool
+	}{
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeA, Value: "127.0.0.1"}, true},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeA, Value: "::1"}, false},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeA, Value: "127.0.0.256"}, false},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeAAAA, Value: "2001:db8::1"}, true},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeAAAA, Value: "127.0.0.1"}, false},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeAAAA, Value: "::ffff:127.0.0.1"}, false},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeCNAME, Value: "example.com"}, true},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeCNAME, Value: "example.com."}, true},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeCNAME, Value: "_x.acm-validations.aws"}, true},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeNS, Value: "_ns.example.com"}, true},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeCNAME, Value: "-example.com"}, false},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeCNAME, Value: "example..com"}, false},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeNS, Value: "ns1.example.com"}, true},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeNS, Value: "ns1.exam ple.com"}, false},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeNS, Value: strings.Repeat("a", 64) + ".com"}, false},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeTXT, Value: "v=spf1 -all"}, true},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeTXT, Value: strings.Repeat("a", 255)}, true},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeTXT, Value: strings.Repeat("a", 256)}, false},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeTXT, Value: "line1\nline2"}, false},
+		{ddnsnow.Record{Type: ddnsnow.RecordTypeTXT, Value: ""}, false},
+		{ddnsnow.Record{Type: "MX", Value: "mail.example.com"}, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.record.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("Validate(%v): unexpected error: %v", tt.record, err)
+		}
+		if !tt.valid && !errors.Is(err, ddnsnow.ErrInvalidRecord) {
+			t.Errorf("Validate(%v): expected %v, got %v", tt.record, ddnsnow.ErrInvalidRecord, err)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ddnsnow
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// zoneWildcardTypes lists the record types published for subdomains too when
+// wildcard resolution is enabled. NS records delegate the whole subtree
+// anyway.
+var zoneWildcardTypes = []RecordType{
+	RecordTypeA,
+	RecordTypeAAAA,
+	RecordTypeCNAME,
+	RecordTypeTXT,
+}
+
+// WriteZone writes the records of settings to w as a BIND-style zone file
+// fragment relative to the domain. Wildcard resolution is written as the
+// records of the domain repeated for the "*" owner, so it is lost for a
+// domain without records.
+func WriteZone(w io.Writer, settings *Settings) error {
+	var b strings.Builder
+	writeOwner := func(owner string, types []RecordType) {
+		for _, typ := range types {
+			for _, value := range settings.Records[typ] {
+				fmt.Fprintf(&b, "%s\tIN\t%s\t%s\n", owner, typ, zoneData(typ, value))
+			}
+		}
+	}
+
+	writeOwner("@", RecordTypes)
+	if settings.EnableWildcard {
+		writeOwner("*", zoneWildcardTypes)
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("write zone: %w", err)
+	}
+	return nil
+}
+
+// zoneData returns the presentation format of value as the data of a record
+// of typ.
+func zoneData(typ RecordType, value string) string {
+	switch typ {
+	case RecordTypeCNAME, RecordTypeNS:
+		// DDNS Now takes fully qualified names, which must not be read
+		// relative to the origin.
+		if !strings.HasSuffix(value, ".") {
+			return value + "."
+		}
+		return value
+	case RecordTypeTXT:
+		var strs []string
+		for len(value) > maxTXTLength {
+			strs = append(strs, quoteZoneString(value[:maxTXTLength]))
+			value = value[maxTXTLength:]
+		}
+		return strings.Join(append(strs, quoteZoneString(value)), " ")
+	default:
+		return value
+	}
+}
+
+// quoteZoneString quotes s as a character-string, escaping quotes,
+// backslashes and unprintable bytes.
+func quoteZoneString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ' || c > '~':
+			fmt.Fprintf(&b, "\\%03d", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// ParseZone reads the records of a BIND-style zone file fragment, e.g. one
+// written by WriteZone. Records owned by "@", or by $ORIGIN once set, are
+// returned as records of the domain. Records owned by "*" only tell whether
+// wildcard resolution is enabled. Every record is validated.
+func ParseZone(r io.Reader) (records []Record, wildcard bool, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, false, fmt.Errorf("read zone: %w", err)
+	}
+	lines, err := tokenizeZone(string(data))
+	if err != nil {
+		return nil, false, err
+	}
+
+	var origin, owner string
+	for _, line := range lines {
+		tokens := line.tokens
+
+		if !line.continued && !tokens[0].quoted && strings.HasPrefix(tokens[0].text, "$") {
+			switch strings.ToUpper(tokens[0].text) {
+			case "$ORIGIN":
+				if len(tokens) != 2 || !strings.HasSuffix(tokens[1].text, ".") {
+					return nil, false, fmt.Errorf("zone line %d: $ORIGIN takes a fully qualified name", line.number)
+				}
+				origin = strings.ToLower(tokens[1].text)
+			case "$TTL":
+			default:
+				return nil, false, fmt.Errorf("zone line %d: unsupported directive %s", line.number, tokens[0].text)
+			}
+			continue
+		}
+
+		if !line.continued {
+			owner, err = zoneOwner(tokens[0].text, origin)
+			if err != nil {
+				return nil, false, fmt.Errorf("zone line %d: %w", line.number, err)
+			}
+			tokens = tokens[1:]
+		} else if owner == "" {
+			return nil, false, fmt.Errorf("zone line %d: missing owner", line.number)
+		}
+
+		// The TTL and the class may precede the type in either order.
+		for range 2 {
+			if len(tokens) > 0 && (isZoneTTL(tokens[0].text) || strings.EqualFold(tokens[0].text, "IN")) {
+				tokens = tokens[1:]
+			}
+		}
+		if len(tokens) < 2 {
+			return nil, false, fmt.Errorf("zone line %d: missing record type or data", line.number)
+		}
+
+		record := Record{Type: RecordType(strings.ToUpper(tokens[0].text))}
+		data := tokens[1:]
+		switch record.Type {
+		case RecordTypeA, RecordTypeAAAA, RecordTypeCNAME, RecordTypeNS:
+			if len(data) != 1 {
+				return nil, false, fmt.Errorf("zone line %d: %s record takes a single value", line.number, record.Type)
+			}
+			record.Value = data[0].text
+			if record.Type == RecordTypeCNAME || record.Type == RecordTypeNS {
+				if !strings.HasSuffix(record.Value, ".") && origin != "" {
+					record.Value += "." + origin
+				}
+				record.Value = strings.TrimSuffix(record.Value, ".")
+			}
+		case RecordTypeTXT:
+			for _, token := range data {
+				record.Value += token.text
+			}
+		default:
+			return nil, false, fmt.Errorf("zone line %d: %w: unsupported record type %q", line.number, ErrInvalidRecord, tokens[0].text)
+		}
+		if err := record.Validate(); err != nil {
+			return nil, false, fmt.Errorf("zone line %d: %w", line.number, err)
+		}
+
+		if owner == "*" {
+			wildcard = true
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, wildcard, nil
+}
+
+// zoneOwner returns "@" for the domain and "*" for its wildcard, which are
+// the only owners supported.
+func zoneOwner(name, origin string) (string, error) {
+	name = strings.ToLower(name)
+	switch {
+	case name == "@" || origin != "" && name == origin:
+		return "@", nil
+	case name == "*" || origin != "" && name == "*."+origin:
+		return "*", nil
+	default:
+		return "", fmt.Errorf("unsupported owner %q, only @ and * are supported", name)
+	}
+}
+
+// isZoneTTL tells whether s is a TTL, e.g. 3600 or 1h30m.
+func isZoneTTL(s string) bool {
+	if s == "" || s[0] < '0' || s[0] > '9' {
+		return false
+	}
+	for _, c := range strings.ToLower(s) {
+		if !(c >= '0' && c <= '9' || strings.ContainsRune("smhdw", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// zoneToken is a field of a zone file with the escapes resolved.
+type zoneToken struct {
+	text   string
+	quoted bool
+}
+
+// zoneLine is an entry of a zone file, which may span several lines within
+// parentheses.
+type zoneLine struct {
+	number int
+	tokens []zoneToken
+	// continued tells whether the entry starts with a blank, so that it
+	// belongs to the owner of the previous one.
+	continued bool
+}
+
+// tokenizeZone splits a zone file into entries of tokens, dropping comments.
+func tokenizeZone(data string) ([]zoneLine, error) {
+	var lines []zoneLine
+	number := 1
+	current := zoneLine{number: number}
+	depth := 0
+
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '\n':
+			number++
+			i++
+			if depth > 0 {
+				continue
+			}
+			if len(current.tokens) > 0 {
+				lines = append(lines, current)
+			}
+			current = zoneLine{number: number}
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			if i == 0 || data[i-1] == '\n' && depth == 0 {
+				current.continued = true
+			}
+			i++
+			continue
+		case c == ';':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			continue
+		case c == '(':
+			depth++
+			i++
+			continue
+		case c == ')':
+			if depth == 0 {
+				return nil, fmt.Errorf("zone line %d: unbalanced parenthesis", number)
+			}
+			depth--
+			i++
+			continue
+		}
+
+		quoted := c == '"'
+		if quoted {
+			i++
+		}
+		var text strings.Builder
+		for {
+			if i >= len(data) {
+				if quoted {
+					return nil, fmt.Errorf("zone line %d: unterminated quoted string", number)
+				}
+				break
+			}
+			c := data[i]
+			if quoted && c == '"' {
+				i++
+				break
+			}
+			if !quoted && strings.IndexByte(" \t\r\n;()\"", c) >= 0 {
+				break
+			}
+			if c == '\n' {
+				number++
+			}
+			if c != '\\' {
+				text.WriteByte(c)
+				i++
+				continue
+			}
+
+			// An escape is either three decimal digits or a single
+			// character taken literally.
+			if i+3 < len(data) && isDigits(data[i+1:i+4]) {
+				n, _ := strconv.Atoi(data[i+1 : i+4])
+				if n > 255 {
+					return nil, fmt.Errorf("zone line %d: invalid escape \\%s", number, data[i+1:i+4])
+				}
+				text.WriteByte(byte(n))
+				i += 4
+				continue
+			}
+			if i+1 >= len(data) {
+				return nil, fmt.Errorf("zone line %d: incomplete escape", number)
+			}
+			text.WriteByte(data[i+1])
+			i += 2
+		}
+		current.tokens = append(current.tokens, zoneToken{text: text.String(), quoted: quoted})
+	}
+
+	if depth > 0 {
+		return nil, fmt.Errorf("zone line %d: unbalanced parenthesis", number)
+	}
+	if len(current.tokens) > 0 {
+		lines = append(lines, current)
+	}
+	return lines, nil
+}
+
+// isDigits tells whether s consists of decimal digits.
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
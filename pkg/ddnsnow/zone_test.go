@@ -0,0 +1,150 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ddnsnow_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"terraform-provider-ddnsnow/pkg/ddnsnow"
+	"testing"
+)
+
+func TestWriteZone(t *testing.T) {
+	settings := &ddnsnow.Settings{
+		Records: map[ddnsnow.RecordType][]string{
+			ddnsnow.RecordTypeA:   {"192.0.2.1"},
+			ddnsnow.RecordTypeNS:  {"ns1.example.com", "ns2.example.com."},
+			ddnsnow.RecordTypeTXT: {`say "hi"; C:\`, "v=spf1 -all"},
+		},
+		EnableWildcard: true,
+	}
+
+	var b strings.Builder
+	if err := ddnsnow.WriteZone(&b, settings); err != nil {
+		t.Fatalf("WriteZone: %v", err)
+	}
+
+	want := "@\tIN\tA\t192.0.2.1\n" +
+		"@\tIN\tNS\tns1.example.com.\n" +
+		"@\tIN\tNS\tns2.example.com.\n" +
+		"@\tIN\tTXT\t\"say \\\"hi\\\"; C:\\\\\"\n" +
+		"@\tIN\tTXT\t\"v=spf1 -all\"\n" +
+		"*\tIN\tA\t192.0.2.1\n" +
+		"*\tIN\tTXT\t\"say \\\"hi\\\"; C:\\\\\"\n" +
+		"*\tIN\tTXT\t\"v=spf1 -all\"\n"
+	if got := b.String(); got != want {
+		t.Fatalf("unexpected zone:\n%s", got)
+	}
+}
+
+func TestZoneRoundTrip(t *testing.T) {
+	tests := []*ddnsnow.Settings{
+		{
+			Records: map[ddnsnow.RecordType][]string{
+				ddnsnow.RecordTypeA:    {"192.0.2.1"},
+				ddnsnow.RecordTypeAAAA: {"2001:db8::1"},
+				ddnsnow.RecordTypeNS:   {"ns1.example.com", "ns2.example.com"},
+				ddnsnow.RecordTypeTXT:  {`quote " and backslash \`, "semicolon ; (parenthesis)", strings.Repeat("a", 255)},
+			},
+			EnableWildcard: true,
+		},
+		{
+			Records: map[ddnsnow.RecordType][]string{
+				ddnsnow.RecordTypeCNAME: {"example.com"},
+			},
+		},
+		{
+			Records: map[ddnsnow.RecordType][]string{},
+		},
+	}
+
+	for _, settings := range tests {
+		var b strings.Builder
+		if err := ddnsnow.WriteZone(&b, settings); err != nil {
+			t.Fatalf("WriteZone: %v", err)
+		}
+
+		records, wildcard, err := ddnsnow.ParseZone(strings.NewReader(b.String()))
+		if err != nil {
+			t.Fatalf("ParseZone(%q): %v", b.String(), err)
+		}
+
+		var want []ddnsnow.Record
+		for _, typ := range ddnsnow.RecordTypes {
+			for _, value := range settings.Records[typ] {
+				want = append(want, ddnsnow.Record{Type: typ, Value: value})
+			}
+		}
+		if !slices.Equal(records, want) {
+			t.Fatalf("unexpected records of %q: %v", b.String(), records)
+		}
+		if wildcard != settings.EnableWildcard {
+			t.Fatalf("unexpected wildcard of %q: %t", b.String(), wildcard)
+		}
+	}
+}
+
+func TestParseZone(t *testing.T) {
+	zone := `$ORIGIN example.f5.si.
+$TTL 3600
+; The domain itself.
+example.f5.si. 300 IN A 192.0.2.1 ; trailing comment
+               IN 1h AAAA 2001:db8::1
+@ NS ns1
+@ NS ns2.example.com.
+@ TXT ( "split "
+        "value" )
+@ TXT unquoted\032\"escaped\"
+*.example.f5.si. A 192.0.2.1
+`
+
+	records, wildcard, err := ddnsnow.ParseZone(strings.NewReader(zone))
+	if err != nil {
+		t.Fatalf("ParseZone: %v", err)
+	}
+
+	want := []ddnsnow.Record{
+		{Type: ddnsnow.RecordTypeA, Value: "192.0.2.1"},
+		{Type: ddnsnow.RecordTypeAAAA, Value: "2001:db8::1"},
+		{Type: ddnsnow.RecordTypeNS, Value: "ns1.example.f5.si"},
+		{Type: ddnsnow.RecordTypeNS, Value: "ns2.example.com"},
+		{Type: ddnsnow.RecordTypeTXT, Value: "split value"},
+		{Type: ddnsnow.RecordTypeTXT, Value: `unquoted "escaped"`},
+	}
+	if !slices.Equal(records, want) {
+		t.Fatalf("unexpected records: %v", records)
+	}
+	if !wildcard {
+		t.Fatalf("wildcard is not enabled")
+	}
+}
+
+func TestParseZoneFails(t *testing.T) {
+	tests := map[string]struct {
+		zone    string
+		invalid bool
+	}{
+		"other owner":        {zone: "www IN A 192.0.2.1\n"},
+		"missing owner":      {zone: " IN A 192.0.2.1\n"},
+		"missing data":       {zone: "@ IN A\n"},
+		"unterminated quote": {zone: "@ IN TXT \"value\n"},
+		"unbalanced":         {zone: "@ IN TXT ( \"value\"\n"},
+		"directive":          {zone: "$INCLUDE other.zone\n"},
+		"unsupported type":   {zone: "@ IN MX 10 mail.example.com.\n", invalid: true},
+		"invalid address":    {zone: "@ IN A 2001:db8::1\n", invalid: true},
+		"invalid TXT":        {zone: "@ IN TXT \"line\\010break\"\n", invalid: true},
+	}
+
+	for name, tt := range tests {
+		_, _, err := ddnsnow.ParseZone(strings.NewReader(tt.zone))
+		if err == nil {
+			t.Errorf("ParseZone(%s): expected an error", name)
+			continue
+		}
+		if tt.invalid != errors.Is(err, ddnsnow.ErrInvalidRecord) {
+			t.Errorf("ParseZone(%s): unexpected error: %v", name, err)
+		}
+	}
+}